@@ -10,6 +10,7 @@ import (
 	"gost/internal/ecs"
 	"gost/internal/events"
 
+	"gost/internal/systems/clipboard"
 	"gost/internal/systems/config"
 	"gost/internal/systems/cursor"
 	"gost/internal/systems/hotreload"
@@ -34,6 +35,7 @@ type GameSystems struct {
 	Cursor     *cursor.System
 	Input      *input.System
 	Selection  *selection.System
+	Clipboard  *clipboard.System
 	Scrollback *scrollback.System
 	Overlay    *overlay.System
 	Parser     *parser.System
@@ -48,6 +50,7 @@ func initSystems(bus *events.Bus, world *ecs.World) *GameSystems {
 	term := components.NewTermBuffer(80, 24)
 	term.AttachBus(bus)
 	sb := components.NewScrollback(1000)
+	term.AttachScrollback(sb)
 
 	// Config
 	cfg := config.NewSystem(bus, "config.json")
@@ -65,8 +68,16 @@ func initSystems(bus *events.Bus, world *ecs.World) *GameSystems {
 	selectionSys := selection.NewSystem(renderSys.Buffer(), 7, 14, bus)
 	scrollbackSys := scrollback.NewSystem(bus, term, sb)
 	parserSys := parser.NewSystem(bus, term)
+	inputSys.AttachModes(parserSys.Modes())
+	renderSys.AttachModes(parserSys.Modes())
+	cursorSys.AttachModes(parserSys.Modes())
+	inputSys.AttachKeyboard(parserSys.Keyboard())
 	ptySys := pty.NewSystem(bus)
-	overlaySys := overlay.NewSystem()
+	overlaySys := overlay.NewSystem(bus)
+	clipboardSys := clipboard.NewSystem(bus)
+
+	// Systems built after config missed its initial announcement.
+	bus.Publish("config_loaded", cfg.Data())
 
 	return &GameSystems{
 		Config:     cfg,
@@ -75,6 +86,7 @@ func initSystems(bus *events.Bus, world *ecs.World) *GameSystems {
 		Cursor:     cursorSys,
 		Input:      inputSys,
 		Selection:  selectionSys,
+		Clipboard:  clipboardSys,
 		Scrollback: scrollbackSys,
 		Overlay:    overlaySys,
 		Parser:     parserSys,
@@ -94,6 +106,7 @@ func registerSystems(world *ecs.World, s *GameSystems) {
 	world.AddSystem(s.Scrollback, ecs.PriorityScrollback)
 	world.AddSystem(s.Render, ecs.PriorityRender)
 	world.AddSystem(s.Selection, ecs.PrioritySelection)
+	world.AddSystem(s.Clipboard, ecs.PriorityClipboard)
 	world.AddSystem(s.Cursor, ecs.PriorityCursor)
 	world.AddSystem(s.Overlay, ecs.PriorityOverlay)
 }
@@ -102,12 +115,16 @@ func registerSystems(world *ecs.World, s *GameSystems) {
 // Game: Ebiten integration — drives ECS update loop and rendering.
 // -----------------------------------------------------------------------------
 type Game struct {
-	world   *ecs.World
-	systems *GameSystems
-	bus     *events.Bus
-	started time.Time
+	world    *ecs.World
+	systems  *GameSystems
+	bus      *events.Bus
+	started  time.Time
+	titleSub <-chan events.Event // window titles set by OSC 0/2
 }
 
+// defaultTitle is shown until the shell sets one, and when it clears it.
+const defaultTitle = "GoST — Modular ECS Terminal Emulator"
+
 // StartGame initializes ECS, systems, and starts Ebiten loop.
 func StartGame() error {
 	bus := events.NewBus()
@@ -117,13 +134,14 @@ func StartGame() error {
 	registerSystems(world, systems)
 
 	game := &Game{
-		world:   world,
-		systems: systems,
-		bus:     bus,
-		started: time.Now(),
+		world:    world,
+		systems:  systems,
+		bus:      bus,
+		started:  time.Now(),
+		titleSub: bus.Subscribe("title_changed"),
 	}
 
-	ebiten.SetWindowTitle("GoST — Modular ECS Terminal Emulator")
+	ebiten.SetWindowTitle(defaultTitle)
 	ebiten.SetWindowResizable(true)
 	ebiten.SetWindowSize(960, 540)
 
@@ -136,9 +154,27 @@ func StartGame() error {
 
 func (g *Game) Update() error {
 	g.world.Update()
+	g.applyTitle()
 	return nil
 }
 
+// applyTitle sets the window title from the latest "title_changed" event.
+func (g *Game) applyTitle() {
+	for {
+		select {
+		case evt := <-g.titleSub:
+			if title, ok := evt.(string); ok {
+				if title == "" {
+					title = defaultTitle
+				}
+				ebiten.SetWindowTitle(title)
+			}
+		default:
+			return
+		}
+	}
+}
+
 func (g *Game) Draw(screen *ebiten.Image) {
 	if g.systems.Render != nil {
 		g.systems.Render.Draw(screen)
@@ -5,6 +5,7 @@ go 1.24.4
 require (
 	github.com/creack/pty v1.1.24
 	github.com/hajimehoshi/ebiten/v2 v2.9.3
+	github.com/jezek/xgb v1.1.1
 	golang.org/x/image v0.31.0
 )
 
@@ -12,7 +13,6 @@ require (
 	github.com/ebitengine/gomobile v0.0.0-20250923094054-ea854a63cce1 // indirect
 	github.com/ebitengine/hideconsole v1.0.0 // indirect
 	github.com/ebitengine/purego v0.9.0 // indirect
-	github.com/jezek/xgb v1.1.1 // indirect
 	golang.org/x/sync v0.17.0 // indirect
 	golang.org/x/sys v0.37.0 // indirect
 )
@@ -0,0 +1,16 @@
+package components
+
+// -----------------------------------------------------------------------------
+// Clipboard Requests
+// -----------------------------------------------------------------------------
+
+// ClipboardRequest is an OSC 52 request from a program, published by the
+// parser on "osc52_request". Data is base64-encoded text to store, or "?"
+// to query the current contents.
+type ClipboardRequest struct {
+	Selection string // selection targets ("c", "p", "s", "0".."7"); "" means "s0"
+	Data      string
+}
+
+// IsQuery reports whether the request asks for the clipboard contents.
+func (r ClipboardRequest) IsQuery() bool { return r.Data == "?" }
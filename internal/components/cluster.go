@@ -0,0 +1,72 @@
+package components
+
+// -----------------------------------------------------------------------------
+// Grapheme Clusters
+// -----------------------------------------------------------------------------
+
+// Most cells hold a single rune, stored inline in Glyph.Rune. Cells holding a
+// multi-rune grapheme cluster (combining marks, ZWJ emoji, flags) reference
+// an interned string in this side table instead, keeping Glyph small and
+// comparable. Overwritten clusters stay in the table until the next sweep.
+var clusters internTable[string]
+
+// maxClusterRunes caps a cluster's length; further marks are dropped, so a
+// stream of combining characters cannot grow a cell without bound.
+const maxClusterRunes = 16
+
+// internCluster returns the side-table id (1-based) for text.
+func internCluster(text string) uint32 {
+	return clusters.intern(text)
+}
+
+func clusterText(id uint32) string {
+	text, _ := clusters.lookup(id)
+	return text
+}
+
+// Text returns the full grapheme cluster stored in the cell.
+func (g Glyph) Text() string {
+	if g.Cluster != 0 {
+		return clusterText(g.Cluster)
+	}
+	if g.Rune == 0 {
+		return ""
+	}
+	return string(g.Rune)
+}
+
+// LastRune returns the final rune of the cell's cluster.
+func (g Glyph) LastRune() rune {
+	if g.Cluster == 0 {
+		return g.Rune
+	}
+	runes := []rune(clusterText(g.Cluster))
+	if len(runes) == 0 {
+		return g.Rune
+	}
+	return runes[len(runes)-1]
+}
+
+// RuneCount returns how many runes make up the cell's cluster.
+func (g Glyph) RuneCount() int {
+	if g.Cluster == 0 {
+		if g.Rune == 0 {
+			return 0
+		}
+		return 1
+	}
+	return len([]rune(clusterText(g.Cluster)))
+}
+
+// AppendRune extends the cell's cluster with r (e.g. a combining mark),
+// unless it already holds maxClusterRunes runes.
+func (g *Glyph) AppendRune(r rune) {
+	if g.Rune == 0 {
+		g.Rune = r
+		return
+	}
+	if g.RuneCount() >= maxClusterRunes {
+		return
+	}
+	g.Cluster = internCluster(g.Text() + string(r))
+}
@@ -0,0 +1,113 @@
+package components
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClusterRoundTrip(t *testing.T) {
+	tests := []string{
+		"e\u0301",                    // e + combining acute
+		"a\u0323\u0308",              // two combining marks
+		"\U0001F44D\U0001F3FD",       // emoji + skin tone modifier
+		"\U0001F469\u200D\U0001F4BB", // ZWJ sequence
+		"\U0001F1EF\U0001F1F5",       // regional indicator pair
+		"x",                          // single rune stays inline
+	}
+	for _, text := range tests {
+		var g Glyph
+		for _, r := range text {
+			g.AppendRune(r)
+		}
+		runes := []rune(text)
+		if got := g.Text(); got != text {
+			t.Errorf("Text() = %q, want %q", got, text)
+		}
+		if got := g.LastRune(); got != runes[len(runes)-1] {
+			t.Errorf("%q: LastRune() = %q, want %q", text, got, runes[len(runes)-1])
+		}
+		if got := g.RuneCount(); got != len(runes) {
+			t.Errorf("%q: RuneCount() = %d, want %d", text, got, len(runes))
+		}
+		if g.Rune != runes[0] {
+			t.Errorf("%q: Rune = %q, want %q", text, g.Rune, runes[0])
+		}
+		if (g.Cluster != 0) != (len(runes) > 1) {
+			t.Errorf("%q: Cluster = %d", text, g.Cluster)
+		}
+	}
+}
+
+func TestClusterCap(t *testing.T) {
+	g := Glyph{Rune: 'a'}
+	for i := 0; i < 100; i++ {
+		g.AppendRune('\u0301')
+	}
+	if got := g.RuneCount(); got != maxClusterRunes {
+		t.Fatalf("RuneCount() = %d, want %d", got, maxClusterRunes)
+	}
+	want := "a" + strings.Repeat("\u0301", maxClusterRunes-1)
+	if got := g.Text(); got != want {
+		t.Fatalf("Text() = %q, want %q", got, want)
+	}
+}
+
+func TestCollectReclaimsClusters(t *testing.T) {
+	sb := NewScrollback(10)
+	tb := NewTermBuffer(4, 2)
+	tb.AttachScrollback(sb)
+
+	// One cell kept on screen, one pushed to scrollback, and a cluster
+	// built up and then overwritten.
+	for _, r := range "\u0300\u0301\u0302" {
+		tb.AppendRune(0, 0, r)
+	}
+	screen := tb.GetRune(0, 0)
+	var history Glyph
+	history.AppendRune('o')
+	history.AppendRune('\u0308')
+	sb.PushLine([]Glyph{history})
+	tb.AppendRune(1, 1, '\u0303')
+	tb.SetRune(1, 1, 'z', ColorDefault, ColorDefault)
+
+	before := clusters.count()
+	collect()
+	if got := clusters.count(); got >= before {
+		t.Fatalf("collect kept %d of %d clusters", got, before)
+	}
+	if got := tb.GetRune(0, 0).Text(); got != screen.Text() || got != " \u0300\u0301\u0302" {
+		t.Fatalf("screen cell = %q", got)
+	}
+	if got := sb.GetLine(0)[0].Text(); got != "o\u0308" {
+		t.Fatalf("scrollback cell = %q", got)
+	}
+
+	// Freed ids are handed out again.
+	n := len(clusters.vals)
+	if id := internCluster("q\u0301"); int(id) > n {
+		t.Fatalf("new cluster got fresh id %d", id)
+	}
+}
+
+func TestCollectKeepsOtherBuffers(t *testing.T) {
+	a := NewTermBuffer(4, 1)
+	b := NewTermBuffer(4, 1)
+	sb := NewScrollback(10)
+	b.AttachScrollback(sb)
+
+	b.AppendRune(0, 0, '\u0301')
+	var history Glyph
+	history.AppendRune('u')
+	history.AppendRune('\u0308')
+	sb.PushLine([]Glyph{history})
+
+	// A sweep must see b's screen and scrollback, not just a's.
+	a.SetGlyph(0, 0, Glyph{Rune: 'x'})
+	collect()
+	if got := b.GetRune(0, 0).Text(); got != " \u0301" {
+		t.Fatalf("cluster on another buffer = %q", got)
+	}
+	if got := sb.GetLine(0)[0].Text(); got != "u\u0308" {
+		t.Fatalf("cluster in another buffer's scrollback = %q", got)
+	}
+}
@@ -0,0 +1,47 @@
+package components
+
+// -----------------------------------------------------------------------------
+// Hyperlinks (OSC 8)
+// -----------------------------------------------------------------------------
+
+// Cells inside an OSC 8 span carry the id of an interned link, so a link
+// costs one uint32 per cell. Cells sharing an id belong to the same link
+// and are highlighted together. A link id stays valid while a cell or an
+// open span (see TermBuffer.OpenLink) still refers to it.
+var links internTable[linkKey]
+
+// linkKey identifies a link: its URI plus the optional "id=" parameter that
+// lets one link span several separately printed runs.
+type linkKey struct {
+	uri, id string
+}
+
+// InternLink returns the table id (1-based) for uri with the given OSC 8
+// id parameter, which may be empty.
+func InternLink(uri, id string) uint32 {
+	return links.intern(linkKey{uri, id})
+}
+
+// OpenLink interns uri and records it as the link applied to cells printed
+// from now on, so a sweep keeps it before any cell holds it. An empty uri
+// closes the span and returns 0.
+func (tb *TermBuffer) OpenLink(uri, id string) uint32 {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.openLink = 0
+	if uri != "" {
+		tb.openLink = InternLink(uri, id)
+	}
+	return tb.openLink
+}
+
+// LinkURI returns the URI of link id, or "" if there is none.
+func LinkURI(id uint32) string {
+	key, _ := links.lookup(id)
+	return key.uri
+}
+
+// URI returns the hyperlink target of the cell, or "" if it has none.
+func (g Glyph) URI() string {
+	return LinkURI(g.Link)
+}
@@ -0,0 +1,76 @@
+package components
+
+import "testing"
+
+func TestInternLink(t *testing.T) {
+	a := InternLink("https://example.com/a", "")
+	if a == 0 {
+		t.Fatal("InternLink returned 0")
+	}
+	if got := InternLink("https://example.com/a", ""); got != a {
+		t.Fatalf("same link interned as %d and %d", a, got)
+	}
+	b := InternLink("https://example.com/a", "x")
+	if b == a {
+		t.Fatal("id parameter did not make a distinct link")
+	}
+	if got := LinkURI(b); got != "https://example.com/a" {
+		t.Fatalf("LinkURI(%d) = %q", b, got)
+	}
+	if got := (Glyph{Link: a}).URI(); got != "https://example.com/a" {
+		t.Fatalf("URI() = %q", got)
+	}
+	if got := LinkURI(0); got != "" {
+		t.Fatalf("LinkURI(0) = %q", got)
+	}
+	if got := LinkURI(1 << 30); got != "" {
+		t.Fatalf("LinkURI(unknown) = %q", got)
+	}
+}
+
+func TestCollectReclaimsLinks(t *testing.T) {
+	tb := NewTermBuffer(4, 1)
+	onScreen := InternLink("https://example.com/screen", "")
+	open := tb.OpenLink("https://example.com/open", "")
+	gone := InternLink("https://example.com/gone", "")
+	tb.SetGlyph(0, 0, Glyph{Rune: 'a', Link: onScreen})
+	tb.SetGlyph(1, 0, Glyph{Rune: 'b', Link: gone})
+	tb.SetGlyph(1, 0, Glyph{Rune: 'c'})
+
+	collect()
+	if got := LinkURI(onScreen); got != "https://example.com/screen" {
+		t.Fatalf("link on screen = %q", got)
+	}
+	if got := LinkURI(open); got != "https://example.com/open" {
+		t.Fatalf("open link = %q", got)
+	}
+	if got := LinkURI(gone); got != "" {
+		t.Fatalf("overwritten link still resolves to %q", got)
+	}
+	n := len(links.vals)
+	if id := InternLink("https://example.com/new", ""); int(id) > n {
+		t.Fatalf("new link got fresh id %d", id)
+	}
+}
+
+func TestCollectKeepsOpenLinks(t *testing.T) {
+	a := NewTermBuffer(4, 1)
+	b := NewTermBuffer(4, 1)
+	open := b.OpenLink("https://example.com/b", "")
+
+	// A sweep must keep the span b has open, whichever buffer starts it.
+	a.OpenLink("", "")
+	collect()
+	if got := LinkURI(open); got != "https://example.com/b" {
+		t.Fatalf("another buffer's open link = %q", got)
+	}
+
+	// Closing the span lets the link go.
+	if id := b.OpenLink("", ""); id != 0 {
+		t.Fatalf("closing a link returned %d", id)
+	}
+	collect()
+	if got := LinkURI(open); got != "" {
+		t.Fatalf("closed link still resolves to %q", got)
+	}
+}
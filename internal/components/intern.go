@@ -0,0 +1,207 @@
+package components
+
+import (
+	"slices"
+	"sync"
+	"weak"
+)
+
+// -----------------------------------------------------------------------------
+// Intern Tables
+// -----------------------------------------------------------------------------
+
+// sweepMin is how many values a table holds before it is first swept.
+const sweepMin = 1024
+
+// internTable hands out small 1-based ids for values kept outside the cells
+// that reference them. Overwriting a cell does not release its id; instead,
+// once a table has doubled since its last sweep, TermBuffer.Collect marks
+// the ids still held by any buffer and the rest are freed for reuse.
+type internTable[K comparable] struct {
+	mu    sync.RWMutex
+	ids   map[K]uint32
+	vals  []K    // by id-1
+	used  []bool // by id-1; false once freed
+	free  []uint32
+	live  int // ids in use
+	limit int // live count that makes the next sweep due
+}
+
+// intern returns the id for v, reusing a freed one if any.
+func (t *internTable[K]) intern(v K) uint32 {
+	t.mu.RLock()
+	id, ok := t.ids[v]
+	t.mu.RUnlock()
+	if ok {
+		return id
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if id, ok := t.ids[v]; ok {
+		return id
+	}
+	if t.ids == nil {
+		t.ids = make(map[K]uint32)
+	}
+	if n := len(t.free); n > 0 {
+		id = t.free[n-1]
+		t.free = t.free[:n-1]
+		t.vals[id-1], t.used[id-1] = v, true
+	} else {
+		t.vals = append(t.vals, v)
+		t.used = append(t.used, true)
+		id = uint32(len(t.vals))
+	}
+	t.ids[v] = id
+	t.live++
+	return id
+}
+
+// lookup returns the value of id, or false if id is 0 or freed.
+func (t *internTable[K]) lookup(id uint32) (K, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if id == 0 || int(id) > len(t.vals) || !t.used[id-1] {
+		var zero K
+		return zero, false
+	}
+	return t.vals[id-1], true
+}
+
+// due reports whether the table has grown enough to be swept.
+func (t *internTable[K]) due() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.live >= max(t.limit, sweepMin)
+}
+
+// sweep frees every id that mark does not report through keep. mark runs
+// with the table locked, so no id is handed out between marking and freeing.
+func (t *internTable[K]) sweep(mark func(keep func(id uint32))) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	kept := make([]bool, len(t.vals)+1)
+	mark(func(id uint32) {
+		if int(id) < len(kept) {
+			kept[id] = true
+		}
+	})
+	var zero K
+	for i, used := range t.used {
+		id := uint32(i + 1)
+		if !used || kept[id] {
+			continue
+		}
+		delete(t.ids, t.vals[i])
+		t.vals[i], t.used[i] = zero, false
+		t.free = append(t.free, id)
+		t.live--
+	}
+	t.limit = 2 * t.live
+}
+
+// count returns how many ids are in use.
+func (t *internTable[K]) count() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.live
+}
+
+// -----------------------------------------------------------------------------
+// Collection
+// -----------------------------------------------------------------------------
+
+// owners holds every TermBuffer still in use. The tables are shared, so a
+// sweep has to mark the cells of all of them, not only the buffer that
+// triggered it.
+var owners struct {
+	mu   sync.Mutex // also serializes sweeps
+	bufs []weak.Pointer[TermBuffer]
+}
+
+// registerOwner adds tb to the buffers a sweep marks.
+func registerOwner(tb *TermBuffer) {
+	owners.mu.Lock()
+	defer owners.mu.Unlock()
+	liveOwners()
+	owners.bufs = append(owners.bufs, weak.Make(tb))
+}
+
+// liveOwners returns the registered buffers that have not been garbage
+// collected and forgets the rest. owners.mu must be held.
+func liveOwners() []*TermBuffer {
+	var live []*TermBuffer
+	kept := owners.bufs[:0]
+	for _, w := range owners.bufs {
+		if tb := w.Value(); tb != nil {
+			live = append(live, tb)
+			kept = append(kept, w)
+		}
+	}
+	clear(owners.bufs[len(kept):])
+	owners.bufs = kept
+	return live
+}
+
+// Collect frees the cluster and link ids that nothing references any more.
+// It does nothing until a table is due for a sweep, so it is cheap to call
+// after every chunk of output.
+func (tb *TermBuffer) Collect() {
+	if clusters.due() || links.due() {
+		collect()
+	}
+}
+
+// collect sweeps both tables, keeping every id held by a cell of either
+// screen or the attached scrollback of any buffer, and each buffer's open
+// hyperlink. Buffers and scrollbacks are read-locked before the tables,
+// the order writers take them in.
+func collect() {
+	owners.mu.Lock()
+	defer owners.mu.Unlock()
+
+	bufs := liveOwners()
+	var sbs []*Scrollback
+	for _, tb := range bufs {
+		tb.mu.RLock()
+		defer tb.mu.RUnlock()
+		if sb := tb.scrollback; sb != nil && !slices.Contains(sbs, sb) {
+			sbs = append(sbs, sb)
+		}
+	}
+	for _, sb := range sbs {
+		sb.mu.RLock()
+		defer sb.mu.RUnlock()
+	}
+
+	each := func(fn func(Glyph)) {
+		for _, tb := range bufs {
+			for _, grid := range [][][]Glyph{tb.primary, tb.alternate} {
+				eachCell(grid, fn)
+			}
+		}
+		for _, sb := range sbs {
+			eachCell(sb.Lines, fn)
+		}
+	}
+	clusters.sweep(func(keep func(uint32)) {
+		each(func(g Glyph) { keep(g.Cluster) })
+	})
+	links.sweep(func(keep func(uint32)) {
+		for _, tb := range bufs {
+			keep(tb.openLink)
+		}
+		each(func(g Glyph) { keep(g.Link) })
+	})
+}
+
+// eachCell calls fn for every cell of rows.
+func eachCell(rows [][]Glyph, fn func(Glyph)) {
+	for _, row := range rows {
+		for _, g := range row {
+			fn(g)
+		}
+	}
+}
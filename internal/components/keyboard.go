@@ -0,0 +1,139 @@
+package components
+
+import "sync"
+
+// -----------------------------------------------------------------------------
+// Kitty Keyboard Protocol
+// -----------------------------------------------------------------------------
+
+// KeyboardFlags are the progressive enhancement flags of the kitty keyboard
+// protocol.
+type KeyboardFlags uint8
+
+const (
+	KeyboardDisambiguate    KeyboardFlags = 1 << iota // CSI u for Esc, Ctrl/Alt chords and the keypad
+	KeyboardReportEvents                              // report repeat and release events
+	KeyboardReportAlternate                           // report the shifted key
+	KeyboardReportAllKeys                             // every key, text included, as an escape code
+	KeyboardReportText                                // append the text a key produces
+)
+
+// KeyboardFlagsMask covers every flag GoST implements.
+const KeyboardFlagsMask = KeyboardDisambiguate | KeyboardReportEvents |
+	KeyboardReportAlternate | KeyboardReportAllKeys | KeyboardReportText
+
+// maxKeyboardStack bounds each screen's stack; pushing onto a full stack
+// drops the oldest entry.
+const maxKeyboardStack = 16
+
+// KeyboardProtocol holds the keyboard reporting state set by the parser and
+// read by the input system: the kitty keyboard flag stacks, one per screen,
+// and xterm's modifyOtherKeys level. The top of the active screen's stack
+// is in effect; an empty stack means legacy encoding.
+type KeyboardProtocol struct {
+	mu        sync.RWMutex
+	stacks    [2][]KeyboardFlags // primary, alternate
+	alt       bool
+	otherKeys int // modifyOtherKeys level, 0–2
+}
+
+// NewKeyboardProtocol returns a protocol state with both stacks empty.
+func NewKeyboardProtocol() *KeyboardProtocol {
+	return &KeyboardProtocol{}
+}
+
+func (k *KeyboardProtocol) stack() *[]KeyboardFlags {
+	if k.alt {
+		return &k.stacks[1]
+	}
+	return &k.stacks[0]
+}
+
+// Flags returns the flags in effect on the active screen.
+func (k *KeyboardProtocol) Flags() KeyboardFlags {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	st := *k.stack()
+	if len(st) == 0 {
+		return 0
+	}
+	return st[len(st)-1]
+}
+
+// Push makes flags current, saving the previous flags (CSI > flags u).
+func (k *KeyboardProtocol) Push(flags KeyboardFlags) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	st := k.stack()
+	if len(*st) == maxKeyboardStack {
+		*st = (*st)[1:]
+	}
+	*st = append(*st, flags&KeyboardFlagsMask)
+}
+
+// Pop removes n entries, restoring the flags pushed before them
+// (CSI < n u). Popping more entries than exist empties the stack.
+func (k *KeyboardProtocol) Pop(n int) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	st := k.stack()
+	*st = (*st)[:max(len(*st)-n, 0)]
+}
+
+// Set changes the current flags (CSI = flags ; mode u): mode 1 replaces
+// them, 2 sets the given bits and 3 clears them.
+func (k *KeyboardProtocol) Set(flags KeyboardFlags, mode int) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	st := k.stack()
+	if len(*st) == 0 {
+		*st = append(*st, 0)
+	}
+	top := &(*st)[len(*st)-1]
+	flags &= KeyboardFlagsMask
+	switch mode {
+	case 1:
+		*top = flags
+	case 2:
+		*top |= flags
+	case 3:
+		*top &^= flags
+	}
+}
+
+// SetAltScreen selects which screen's stack is in effect.
+func (k *KeyboardProtocol) SetAltScreen(alt bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.alt = alt
+}
+
+// Reset empties both stacks and turns modifyOtherKeys off.
+func (k *KeyboardProtocol) Reset() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.stacks = [2][]KeyboardFlags{}
+	k.otherKeys = 0
+}
+
+// -----------------------------------------------------------------------------
+// modifyOtherKeys
+// -----------------------------------------------------------------------------
+
+// SetModifyOtherKeys sets xterm's modifyOtherKeys level (CSI > 4 ; level m).
+// Levels outside 0–2 are ignored.
+func (k *KeyboardProtocol) SetModifyOtherKeys(level int) {
+	if level < 0 || level > 2 {
+		return
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.otherKeys = level
+}
+
+// ModifyOtherKeys returns the modifyOtherKeys level, 0 when off.
+func (k *KeyboardProtocol) ModifyOtherKeys() int {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.otherKeys
+}
@@ -0,0 +1,74 @@
+package components
+
+import "testing"
+
+func TestKeyboardProtocolStack(t *testing.T) {
+	k := NewKeyboardProtocol()
+	if f := k.Flags(); f != 0 {
+		t.Fatalf("initial flags = %d, want 0", f)
+	}
+
+	k.Push(1)
+	k.Push(3)
+	if f := k.Flags(); f != 3 {
+		t.Fatalf("after two pushes flags = %d, want 3", f)
+	}
+	k.Pop(1)
+	if f := k.Flags(); f != 1 {
+		t.Fatalf("after pop flags = %d, want 1", f)
+	}
+	k.Pop(5)
+	if f := k.Flags(); f != 0 {
+		t.Fatalf("after popping past the bottom flags = %d, want 0", f)
+	}
+
+	for i := 0; i < maxKeyboardStack+4; i++ {
+		k.Push(KeyboardFlags(i % 32))
+	}
+	k.Pop(maxKeyboardStack - 1)
+	if f := k.Flags(); f != 4 {
+		t.Fatalf("oldest entries not evicted: flags = %d, want 4", f)
+	}
+}
+
+func TestKeyboardProtocolSet(t *testing.T) {
+	k := NewKeyboardProtocol()
+	k.Set(KeyboardDisambiguate|KeyboardReportEvents, 1)
+	if f := k.Flags(); f != 3 {
+		t.Fatalf("set: flags = %d, want 3", f)
+	}
+	k.Set(KeyboardReportAllKeys, 2)
+	if f := k.Flags(); f != 11 {
+		t.Fatalf("add: flags = %d, want 11", f)
+	}
+	k.Set(KeyboardDisambiguate, 3)
+	if f := k.Flags(); f != 10 {
+		t.Fatalf("remove: flags = %d, want 10", f)
+	}
+	k.Set(0xff, 1)
+	if f := k.Flags(); f != KeyboardFlagsMask {
+		t.Fatalf("unknown bits kept: flags = %d", f)
+	}
+}
+
+func TestKeyboardProtocolPerScreen(t *testing.T) {
+	k := NewKeyboardProtocol()
+	k.Push(1)
+	k.SetAltScreen(true)
+	if f := k.Flags(); f != 0 {
+		t.Fatalf("alternate screen inherited flags %d", f)
+	}
+	k.Push(8)
+	k.SetAltScreen(false)
+	if f := k.Flags(); f != 1 {
+		t.Fatalf("primary flags = %d, want 1", f)
+	}
+	k.SetAltScreen(true)
+	if f := k.Flags(); f != 8 {
+		t.Fatalf("alternate flags = %d, want 8", f)
+	}
+	k.Reset()
+	if f := k.Flags(); f != 0 {
+		t.Fatalf("after reset flags = %d, want 0", f)
+	}
+}
@@ -0,0 +1,105 @@
+package components
+
+import "sync"
+
+// -----------------------------------------------------------------------------
+// DEC Private Modes
+// -----------------------------------------------------------------------------
+
+// Mode identifies a DEC private mode (CSI ? n h / CSI ? n l).
+type Mode int
+
+const (
+	ModeCursorKeys     Mode = 1    // DECCKM — application cursor keys
+	ModeOrigin         Mode = 6    // DECOM — cursor addressing relative to margins
+	ModeAutoWrap       Mode = 7    // DECAWM — wrap at the right margin
+	ModeMouseX10       Mode = 9    // X10 mouse reporting
+	ModeCursorBlink    Mode = 12   // blinking cursor
+	ModeCursorVisible  Mode = 25   // DECTCEM — show cursor
+	ModeAltScreen      Mode = 47   // alternate screen
+	ModeKeypad         Mode = 66   // DECNKM — application keypad
+	ModeMouseNormal    Mode = 1000 // button press/release reporting
+	ModeMouseButton    Mode = 1002 // button-event (drag) tracking
+	ModeMouseAny       Mode = 1003 // any-event (motion) tracking
+	ModeFocusReport    Mode = 1004 // focus in/out reporting
+	ModeMouseUTF8      Mode = 1005 // UTF-8 mouse coordinates
+	ModeMouseSGR       Mode = 1006 // SGR mouse coordinates
+	ModeAltScreenClear Mode = 1047 // alternate screen, cleared on exit
+	ModeSaveCursor     Mode = 1048 // save/restore cursor
+	ModeAltScreenSave  Mode = 1049 // save cursor + cleared alternate screen
+	ModeBracketedPaste Mode = 2004 // wrap pastes in ESC[200~ … ESC[201~
+	ModeSyncOutput     Mode = 2026 // synchronized output (hold redraws)
+)
+
+// defaultModes lists every recognized mode with its power-on state.
+var defaultModes = map[Mode]bool{
+	ModeCursorKeys:     false,
+	ModeOrigin:         false,
+	ModeAutoWrap:       true,
+	ModeMouseX10:       false,
+	ModeCursorBlink:    false,
+	ModeCursorVisible:  true,
+	ModeAltScreen:      false,
+	ModeKeypad:         false,
+	ModeMouseNormal:    false,
+	ModeMouseButton:    false,
+	ModeMouseAny:       false,
+	ModeFocusReport:    false,
+	ModeMouseUTF8:      false,
+	ModeMouseSGR:       false,
+	ModeAltScreenClear: false,
+	ModeSaveCursor:     false,
+	ModeAltScreenSave:  false,
+	ModeBracketedPaste: false,
+	ModeSyncOutput:     false,
+}
+
+// ModeTable is the central, concurrency-safe record of DEC private modes.
+type ModeTable struct {
+	mu    sync.RWMutex
+	state map[Mode]bool
+}
+
+// NewModeTable returns a table holding the power-on defaults.
+func NewModeTable() *ModeTable {
+	m := &ModeTable{}
+	m.Reset()
+	return m
+}
+
+// Reset restores every mode to its power-on default.
+func (m *ModeTable) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state = make(map[Mode]bool, len(defaultModes))
+	for mode, on := range defaultModes {
+		m.state[mode] = on
+	}
+}
+
+// Set updates a recognized mode and reports whether its value changed.
+func (m *ModeTable) Set(mode Mode, on bool) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	old, known := m.state[mode]
+	if !known || old == on {
+		return false
+	}
+	m.state[mode] = on
+	return true
+}
+
+// Enabled reports whether a mode is currently set.
+func (m *ModeTable) Enabled(mode Mode) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state[mode]
+}
+
+// Known reports whether the mode is recognized at all.
+func (m *ModeTable) Known(mode Mode) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.state[mode]
+	return ok
+}
@@ -0,0 +1,14 @@
+package components
+
+// -----------------------------------------------------------------------------
+// Prompts
+// -----------------------------------------------------------------------------
+
+// Prompt is a yes/no question put to the user by the overlay, published on
+// "prompt_request". Answer is called exactly once, from the overlay's ECS
+// update, with the user's choice; prompts the overlay cannot queue are
+// answered false straight away.
+type Prompt struct {
+	Text   string
+	Answer func(ok bool)
+}
@@ -0,0 +1,195 @@
+package components
+
+// -----------------------------------------------------------------------------
+// Reflow
+// -----------------------------------------------------------------------------
+
+// Rows joined by their soft-wrap flag form one logical line. On a width
+// change logical lines are re-wrapped to the new width instead of being
+// truncated, so narrowing and widening the window round-trips the text.
+
+// isBlankCell reports whether g is an untouched blank cell, ignoring the
+// soft-wrap flag.
+func isBlankCell(g Glyph) bool {
+	g.Flags &^= CellWrapped
+	return g == Glyph{Rune: ' '}
+}
+
+// isBlankRow reports whether every cell of row is blank.
+func isBlankRow(row []Glyph) bool {
+	for _, g := range row {
+		if !isBlankCell(g) {
+			return false
+		}
+	}
+	return true
+}
+
+// unwrap joins soft-wrapped rows into logical lines and trims trailing blank
+// cells. The cell at (cx, cy) is located as offset off of line cl (cl is -1
+// when the cursor lies outside rows); that line is kept long enough to hold
+// it.
+func unwrap(rows [][]Glyph, cx, cy int) (lines [][]Glyph, cl, off int) {
+	cl = -1
+	var cur []Glyph
+	for y, row := range rows {
+		wrapped := LineWrapped(row)
+		cells := row
+		// A wide character that did not fit leaves a blank pad on the last
+		// column of the row it wrapped from; it is not part of the text.
+		if wrapped && y+1 < len(rows) && len(row) > 0 && isBlankCell(row[len(row)-1]) &&
+			len(rows[y+1]) > 0 && rows[y+1][0].IsWide() {
+			cells = row[:len(row)-1]
+		}
+		if y == cy {
+			cl, off = len(lines), len(cur)+max(min(cx, len(cells)-1), 0)
+		}
+		for _, g := range cells {
+			g.Flags &^= CellWrapped
+			cur = append(cur, g)
+		}
+		if !wrapped || y == len(rows)-1 {
+			lines = append(lines, cur)
+			cur = nil
+		}
+	}
+
+	for i, line := range lines {
+		keep := 0
+		if i == cl {
+			keep = off + 1
+		}
+		n := len(line)
+		for n > keep && isBlankCell(line[n-1]) {
+			n--
+		}
+		lines[i] = line[:n]
+	}
+	return lines, cl, off
+}
+
+// rewrap splits a logical line into rows of the given width, never splitting
+// a wide character, and flags every row but the last as soft-wrapped. The
+// cell at offset mark of line is reported as (mx, my) within the result.
+func rewrap(line []Glyph, width, mark int) (rows [][]Glyph, mx, my int) {
+	row := blankLine(width)
+	x := 0
+	for i := 0; i < len(line); i++ {
+		g := line[i]
+		if g.IsWideCont() {
+			if i == mark {
+				mx, my = max(x-1, 0), len(rows)
+			}
+			continue // written with its leading cell
+		}
+		n := 1
+		if g.IsWide() {
+			n = 2
+			if width < 2 {
+				g, n = Glyph{Rune: ' ', Fg: g.Fg, Bg: g.Bg}, 1
+			}
+		}
+		if x+n > width {
+			row[width-1].Flags |= CellWrapped
+			rows = append(rows, row)
+			row, x = blankLine(width), 0
+		}
+		if i == mark {
+			mx, my = x, len(rows)
+		}
+		row[x] = g
+		if n == 2 {
+			row[x+1] = Glyph{Fg: g.Fg, Bg: g.Bg, Attr: g.Attr, Flags: CellWideCont, Link: g.Link}
+		}
+		x += n
+	}
+	rows = append(rows, row)
+	return rows, mx, my
+}
+
+// blankLine allocates a row of width blank cells.
+func blankLine(width int) []Glyph {
+	row := make([]Glyph, width)
+	blankRow(row)
+	return row
+}
+
+// reflowGrid re-wraps grid to newW×newH, keeping the cursor (cx, cy) on the
+// same logical cell. Rows pushed off the top to keep the cursor on screen
+// are returned as spill, oldest first, for scrollback.
+func reflowGrid(grid [][]Glyph, newW, newH, cx, cy int) (out, spill [][]Glyph, nx, ny int) {
+	lines, cl, off := unwrap(grid, cx, cy)
+	for i, line := range lines {
+		mark := -1
+		if i == cl {
+			mark = off
+		}
+		rows, mx, my := rewrap(line, newW, mark)
+		if i == cl {
+			nx, ny = mx, len(out)+my
+		}
+		out = append(out, rows...)
+	}
+
+	// Blank rows below the cursor are the first to go.
+	for len(out) > newH && len(out)-1 > ny && isBlankRow(out[len(out)-1]) {
+		out = out[:len(out)-1]
+	}
+	// Then history above the cursor scrolls off; anything still left over
+	// below the cursor is dropped.
+	if excess := len(out) - newH; excess > 0 {
+		top := min(excess, ny)
+		spill, out = out[:top], out[top:]
+		ny -= top
+		out = out[:min(len(out), newH)]
+	}
+	for len(out) < newH {
+		out = append(out, blankLine(newW))
+	}
+	return out, spill, min(nx, newW-1), min(ny, newH-1)
+}
+
+// Reflow re-wraps the stored history to width, dropping the oldest rows if
+// the result exceeds the capacity.
+func (sb *Scrollback) Reflow(width int) {
+	if sb == nil || width <= 0 {
+		return
+	}
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	lines, _, _ := unwrap(sb.Lines, -1, -1)
+	// The newest line may continue onto the screen; keep its wrap flag.
+	tail := len(sb.Lines) > 0 && LineWrapped(sb.Lines[len(sb.Lines)-1])
+	var out [][]Glyph
+	for _, line := range lines {
+		rows, _, _ := rewrap(line, width, -1)
+		out = append(out, rows...)
+	}
+	if tail && len(out) > 0 {
+		last := out[len(out)-1]
+		last[len(last)-1].Flags |= CellWrapped
+	}
+	if len(out) > sb.Max {
+		out = out[len(out)-sb.Max:]
+	}
+	sb.Lines = out
+}
+
+// takeWrappedTail removes and returns the newest rows of history that
+// soft-wrap onto the screen: the start of the screen's first logical line.
+func (sb *Scrollback) takeWrappedTail() [][]Glyph {
+	if sb == nil {
+		return nil
+	}
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	i := len(sb.Lines)
+	for i > 0 && LineWrapped(sb.Lines[i-1]) {
+		i--
+	}
+	tail := append([][]Glyph(nil), sb.Lines[i:]...)
+	sb.Lines = sb.Lines[:i]
+	return tail
+}
@@ -0,0 +1,280 @@
+package components
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gost/internal/events"
+)
+
+// writeText types s into tb the way an autowrapping terminal would, starting
+// at the cursor, and leaves the cursor after the last character.
+func writeText(tb *TermBuffer, s string) {
+	x, y := tb.CursorX, tb.CursorY
+	for _, r := range s {
+		if r == '\n' {
+			x, y = 0, y+1
+			continue
+		}
+		if x == tb.Width {
+			tb.SetWrapped(y, true)
+			x, y = 0, y+1
+		}
+		tb.SetRune(x, y, r, ColorDefault, ColorDefault)
+		x++
+	}
+	tb.SetCursor(x, y)
+}
+
+// rowText returns row y with trailing blanks removed.
+func rowText(tb *TermBuffer, y int) string {
+	var sb strings.Builder
+	for x := 0; x < tb.Width; x++ {
+		g := tb.GetRune(x, y)
+		if !g.IsWideCont() {
+			sb.WriteString(g.Text())
+		}
+	}
+	return strings.TrimRight(sb.String(), " ")
+}
+
+func screenText(tb *TermBuffer) []string {
+	rows := make([]string, tb.Height)
+	for y := range rows {
+		rows[y] = rowText(tb, y)
+	}
+	return rows
+}
+
+func equalRows(a, b []string) bool {
+	return strings.Join(a, "\n") == strings.Join(b, "\n")
+}
+
+func TestResizeShrinkWrapsLines(t *testing.T) {
+	tb := NewTermBuffer(20, 5)
+	writeText(tb, "hello world 12345\n$ ")
+
+	tb.Resize(8, 5)
+	want := []string{"hello wo", "rld 1234", "5", "$", ""}
+	if got := screenText(tb); !equalRows(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for y, wrapped := range []bool{true, true, false, false} {
+		if tb.IsWrapped(y) != wrapped {
+			t.Errorf("row %d wrapped = %v, want %v", y, tb.IsWrapped(y), wrapped)
+		}
+	}
+	if x, y := tb.GetCursor(); x != 2 || y != 3 {
+		t.Fatalf("cursor at (%d,%d), want (2,3)", x, y)
+	}
+}
+
+func TestResizeRoundTrip(t *testing.T) {
+	for _, w := range []int{3, 7, 10, 16, 40} {
+		tb := NewTermBuffer(20, 6)
+		writeText(tb, "the quick brown fox jumps over\nlazy dogs")
+		before := screenText(tb)
+		bx, by := tb.GetCursor()
+
+		tb.Resize(w, 6)
+		tb.Resize(20, 6)
+
+		// Narrow widths push the top of the screen into scrollback; compare
+		// the rows that are still on screen.
+		got := screenText(tb)
+		if w >= 10 && !equalRows(got, before) {
+			t.Errorf("width %d: got %q, want %q", w, got, before)
+		}
+		x, y := tb.GetCursor()
+		if rowText(tb, y) != "lazy dogs" || x != bx {
+			t.Errorf("width %d: cursor at (%d,%d) on %q, was (%d,%d)", w, x, y, rowText(tb, y), bx, by)
+		}
+	}
+}
+
+func TestResizeGrowUnwraps(t *testing.T) {
+	tb := NewTermBuffer(5, 4)
+	writeText(tb, "abcdefghijkl\n")
+	if !tb.IsWrapped(0) || !tb.IsWrapped(1) {
+		t.Fatal("expected the first two rows to be soft-wrapped")
+	}
+
+	tb.Resize(12, 4)
+	if got := rowText(tb, 0); got != "abcdefghijkl" {
+		t.Fatalf("row 0 = %q", got)
+	}
+	if tb.IsWrapped(0) {
+		t.Fatal("row 0 should no longer wrap")
+	}
+	if x, y := tb.GetCursor(); x != 0 || y != 1 {
+		t.Fatalf("cursor at (%d,%d), want (0,1)", x, y)
+	}
+}
+
+func TestResizeCursorAfterFullLine(t *testing.T) {
+	// The cursor just past a line that exactly fills the new width moves to
+	// the start of the next row rather than onto the last character.
+	tb := NewTermBuffer(5, 4)
+	writeText(tb, "abcdefgh")
+	tb.Resize(8, 4)
+	if x, y := tb.GetCursor(); x != 0 || y != 1 || !tb.IsWrapped(0) {
+		t.Fatalf("cursor at (%d,%d) wrapped=%v, want (0,1) wrapped", x, y, tb.IsWrapped(0))
+	}
+}
+
+func TestResizeKeepsWideCharactersWhole(t *testing.T) {
+	tb := NewTermBuffer(6, 4)
+	tb.SetGlyph(0, 0, Glyph{Rune: 'a'})
+	tb.SetGlyph(1, 0, Glyph{Rune: 'b'})
+	tb.SetGlyph(2, 0, Glyph{Rune: '中', Flags: CellWide})
+	tb.SetGlyph(3, 0, Glyph{Flags: CellWideCont})
+	tb.SetGlyph(4, 0, Glyph{Rune: '文', Flags: CellWide})
+	tb.SetGlyph(5, 0, Glyph{Flags: CellWideCont})
+	tb.SetCursor(0, 1)
+
+	tb.Resize(3, 4)
+	if got := rowText(tb, 0); got != "ab" || !tb.IsWrapped(0) {
+		t.Fatalf("row 0 = %q wrapped=%v", got, tb.IsWrapped(0))
+	}
+	if g := tb.GetRune(0, 1); g.Rune != '中' || !g.IsWide() || !tb.GetRune(1, 1).IsWideCont() {
+		t.Fatalf("row 1 starts with %q", g.Rune)
+	}
+
+	// Growing back drops the pad left on row 0.
+	tb.Resize(6, 4)
+	if got := rowText(tb, 0); got != "ab中文" {
+		t.Fatalf("row 0 = %q after grow", got)
+	}
+}
+
+func TestResizeKeepsWideCharacterAttributes(t *testing.T) {
+	link := InternLink("https://example.com/wide", "")
+	fg, bg := RGBColor(1, 2, 3), IndexedColor(4)
+	tb := NewTermBuffer(4, 3)
+	tb.SetGlyph(0, 0, Glyph{Rune: 'a'})
+	tb.SetGlyph(1, 0, Glyph{Rune: 'b'})
+	tb.SetGlyph(2, 0, Glyph{Rune: '中', Fg: fg, Bg: bg, Attr: AttrBold, Flags: CellWide, Link: link})
+	tb.SetGlyph(3, 0, Glyph{Fg: fg, Bg: bg, Attr: AttrBold, Flags: CellWideCont, Link: link})
+	tb.SetCursor(0, 1)
+
+	tb.Resize(3, 3)
+	lead, cont := tb.GetRune(0, 1), tb.GetRune(1, 1)
+	if lead.Rune != '中' || !cont.IsWideCont() {
+		t.Fatalf("row 1 = %q %q", lead.Rune, cont.Rune)
+	}
+	for _, g := range []Glyph{lead, cont} {
+		if g.Link != link || g.Fg != fg || g.Bg != bg || g.Attr != AttrBold {
+			t.Fatalf("cell lost its attributes: %+v", g)
+		}
+	}
+}
+
+func TestResizeSpillsToScrollback(t *testing.T) {
+	bus := events.NewBus()
+	sub := bus.Subscribe("term_scrolled")
+	tb := NewTermBuffer(10, 3)
+	tb.AttachBus(bus)
+	writeText(tb, "one\ntwo\nthree")
+
+	tb.Resize(10, 2)
+	if got := screenText(tb); !equalRows(got, []string{"two", "three"}) {
+		t.Fatalf("screen = %q", got)
+	}
+	select {
+	case evt := <-sub:
+		line := evt.([]Glyph)
+		if len(line) != 10 || line[0].Rune != 'o' {
+			t.Fatalf("spilled %q", string(line[0].Rune))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no line reached scrollback")
+	}
+}
+
+func TestResizeSpillsToAttachedScrollback(t *testing.T) {
+	sb := NewScrollback(100)
+	tb := NewTermBuffer(10, 3)
+	tb.AttachScrollback(sb)
+	writeText(tb, "one\ntwo\nthree")
+
+	tb.Resize(10, 2)
+	if sb.Count() != 1 || strings.TrimRight(lineText(sb.GetLine(0)), " ") != "one" {
+		t.Fatalf("scrollback holds %d lines", sb.Count())
+	}
+}
+
+func TestResizeRejoinsLineAcrossScrollback(t *testing.T) {
+	sb := NewScrollback(100)
+	tb := NewTermBuffer(4, 2)
+	tb.AttachScrollback(sb)
+	writeText(tb, "abcdefg")
+
+	// The line's first row spills, still flagged as wrapping onto the screen.
+	tb.Resize(4, 1)
+	if sb.Count() != 1 || !LineWrapped(sb.GetLine(0)) {
+		t.Fatalf("scrollback holds %d lines", sb.Count())
+	}
+	if got := screenText(tb); !equalRows(got, []string{"efg"}) {
+		t.Fatalf("screen = %q", got)
+	}
+
+	// Widening takes the row back and re-wraps the line as a whole.
+	tb.Resize(8, 2)
+	if sb.Count() != 0 {
+		t.Fatalf("scrollback still holds %d lines", sb.Count())
+	}
+	if got := screenText(tb); !equalRows(got, []string{"abcdefg", ""}) {
+		t.Fatalf("screen = %q", got)
+	}
+	if x, y := tb.GetCursor(); x != 7 || y != 0 {
+		t.Fatalf("cursor at (%d,%d), want (7,0)", x, y)
+	}
+}
+
+func TestResizeReflowsPrimaryUnderAltScreen(t *testing.T) {
+	tb := NewTermBuffer(10, 3)
+	writeText(tb, "0123456789a")
+	tb.EnterAltScreen(true, true)
+	tb.Resize(6, 3)
+	tb.ExitAltScreen(true, false)
+
+	if got := screenText(tb); !equalRows(got, []string{"012345", "6789a", ""}) {
+		t.Fatalf("primary = %q", got)
+	}
+	if x, y := tb.GetCursor(); x != 5 || y != 1 {
+		t.Fatalf("restored cursor at (%d,%d), want (5,1)", x, y)
+	}
+}
+
+func TestScrollbackReflowRoundTrip(t *testing.T) {
+	sb := NewScrollback(100)
+	tb := NewTermBuffer(8, 1)
+	writeText(tb, "abcdefgh")
+	sb.PushLine(tb.Cells[0])
+	tb.Clear()
+	writeText(tb, "xyz")
+	sb.PushLine(tb.Cells[0])
+
+	sb.Reflow(3)
+	var got []string
+	for i := 0; i < sb.Count(); i++ {
+		got = append(got, strings.TrimRight(lineText(sb.GetLine(i)), " "))
+	}
+	if !equalRows(got, []string{"abc", "def", "gh", "xyz"}) {
+		t.Fatalf("narrow = %q", got)
+	}
+
+	sb.Reflow(8)
+	if sb.Count() != 2 || lineText(sb.GetLine(0)) != "abcdefgh" {
+		t.Fatalf("wide = %d lines, first %q", sb.Count(), lineText(sb.GetLine(0)))
+	}
+}
+
+func lineText(line []Glyph) string {
+	var sb strings.Builder
+	for _, g := range line {
+		sb.WriteString(g.Text())
+	}
+	return sb.String()
+}
@@ -12,40 +12,157 @@ import (
 
 // Glyph represents a single cell in the terminal grid.
 type Glyph struct {
-	Rune rune // Unicode codepoint
-	Fg   int  // Foreground color index (0–7)
-	Bg   int  // Background color index (0–7)
+	Rune    rune     // Unicode codepoint (first rune of the cluster)
+	Cluster uint32   // side-table id of a multi-rune cluster, 0 if none
+	Fg      Color    // Foreground color
+	Bg      Color    // Background color
+	Attr    Attr     // SGR rendition attributes
+	Flags   CellFlag // layout flags (wide characters)
+	Link    uint32   // hyperlink table id (OSC 8), 0 if none
 }
 
+// CellFlag marks layout properties of a cell that are not SGR attributes.
+type CellFlag uint8
+
+const (
+	CellWide     CellFlag = 1 << iota // leading half of a double-width character
+	CellWideCont                      // trailing half; carries no rune of its own
+	CellWrapped                       // on a row's last cell: the line soft-wraps onto the next row
+)
+
+// IsWide reports whether g starts a double-width character.
+func (g Glyph) IsWide() bool { return g.Flags&CellWide != 0 }
+
+// IsWideCont reports whether g is the trailing half of a double-width character.
+func (g Glyph) IsWideCont() bool { return g.Flags&CellWideCont != 0 }
+
+// LineWrapped reports whether a row (screen or scrollback) was soft-wrapped,
+// i.e. its text continues on the following row without a newline.
+func LineWrapped(line []Glyph) bool {
+	return len(line) > 0 && line[len(line)-1].Flags&CellWrapped != 0
+}
+
+// Color is a cell color: the terminal default, a 256-color palette index,
+// or a 24-bit RGB value. The zero value is the default color.
+type Color uint32
+
+const (
+	ColorDefault Color = 0
+
+	colorIndexed Color = 1 << 24
+	colorRGB     Color = 2 << 24
+	colorKind    Color = 0xff << 24
+)
+
+// IndexedColor returns a palette color (0–255).
+func IndexedColor(index int) Color {
+	return colorIndexed | Color(uint8(index))
+}
+
+// RGBColor returns a 24-bit truecolor value.
+func RGBColor(r, g, b uint8) Color {
+	return colorRGB | Color(r)<<16 | Color(g)<<8 | Color(b)
+}
+
+// IsDefault reports whether c is the terminal default color.
+func (c Color) IsDefault() bool { return c&colorKind == ColorDefault }
+
+// Index returns the palette index of an indexed color.
+func (c Color) Index() (int, bool) {
+	if c&colorKind != colorIndexed {
+		return 0, false
+	}
+	return int(c & 0xff), true
+}
+
+// RGB returns the components of a truecolor value.
+func (c Color) RGB() (r, g, b uint8, ok bool) {
+	if c&colorKind != colorRGB {
+		return 0, 0, 0, false
+	}
+	return uint8(c >> 16), uint8(c >> 8), uint8(c), true
+}
+
+// Attr is a bitfield of SGR rendition attributes.
+type Attr uint16
+
+const (
+	AttrBold Attr = 1 << iota
+	AttrDim
+	AttrItalic
+	AttrUnderline
+	AttrBlink
+	AttrReverse
+	AttrHidden
+	AttrStrike
+)
+
+// Has reports whether any of the given attribute bits are set.
+func (a Attr) Has(flag Attr) bool { return a&flag != 0 }
+
 // -----------------------------------------------------------------------------
 // TermBuffer
 // -----------------------------------------------------------------------------
 
 // TermBuffer stores the live visible screen contents of the terminal.
 // It supports concurrent access and event-based scrollback integration.
+//
+// Two grids are kept: the primary screen and the alternate screen used by
+// full-screen programs. Cells always points at the active one.
 type TermBuffer struct {
 	mu               sync.RWMutex
 	Width, Height    int
 	Cells            [][]Glyph
 	CursorX, CursorY int
 
-	bus *events.Bus
+	primary   [][]Glyph
+	alternate [][]Glyph
+	altActive bool
+
+	savedX, savedY int // cursor saved on alternate screen entry (DECSET 1049)
+
+	tabStops []bool // one entry per column; true marks a tab stop
+
+	bus        *events.Bus
+	scrollback *Scrollback // receives rows scrolled off the primary screen
+
+	openLink uint32 // hyperlink being applied to printed cells, kept by Collect
+}
+
+// TermSize is a grid size in cells, with the pixel size it covers. It is
+// published on "term_resize" when the window is resized.
+type TermSize struct {
+	Cols, Rows     int
+	PixelW, PixelH int
 }
 
 // NewTermBuffer allocates a clean terminal grid.
 func NewTermBuffer(width, height int) *TermBuffer {
 	tb := &TermBuffer{
-		Width:  width,
-		Height: height,
-		Cells:  make([][]Glyph, height),
-	}
-	for y := range tb.Cells {
-		tb.Cells[y] = make([]Glyph, width)
+		Width:     width,
+		Height:    height,
+		primary:   newGrid(width, height),
+		alternate: newGrid(width, height),
 	}
+	tb.Cells = tb.primary
+	tb.tabStops = resizeTabStops(nil, width)
 	tb.Clear()
+	registerOwner(tb)
 	return tb
 }
 
+// newGrid allocates a width×height grid of blank cells.
+func newGrid(width, height int) [][]Glyph {
+	grid := make([][]Glyph, height)
+	for y := range grid {
+		grid[y] = make([]Glyph, width)
+		for x := range grid[y] {
+			grid[y][x] = Glyph{Rune: ' '}
+		}
+	}
+	return grid
+}
+
 // AttachBus links the terminal to an event bus for async scrollback notifications.
 func (tb *TermBuffer) AttachBus(bus *events.Bus) {
 	tb.mu.Lock()
@@ -53,34 +170,106 @@ func (tb *TermBuffer) AttachBus(bus *events.Bus) {
 	tb.bus = bus
 }
 
+// AttachScrollback makes sb receive rows scrolled off the primary screen
+// directly, in step with the grid, instead of through "term_scrolled".
+func (tb *TermBuffer) AttachScrollback(sb *Scrollback) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.scrollback = sb
+}
+
 // Clear resets all cells to blank space.
 func (tb *TermBuffer) Clear() {
 	tb.mu.Lock()
 	defer tb.mu.Unlock()
-	for y := 0; y < tb.Height; y++ {
-		for x := 0; x < tb.Width; x++ {
-			tb.Cells[y][x] = Glyph{Rune: ' ', Fg: 7, Bg: 0}
-		}
-	}
+	tb.clearGrid(tb.Cells)
 	tb.CursorX, tb.CursorY = 0, 0
 }
 
 // SetRune writes a rune at (x, y).
-func (tb *TermBuffer) SetRune(x, y int, r rune, fg, bg int) {
+func (tb *TermBuffer) SetRune(x, y int, r rune, fg, bg Color) {
 	tb.mu.Lock()
 	defer tb.mu.Unlock()
 	if x < 0 || y < 0 || y >= tb.Height || x >= tb.Width {
 		return
 	}
+	tb.splitWide(x, y)
 	tb.Cells[y][x] = Glyph{Rune: r, Fg: fg, Bg: bg}
 }
 
+// SetGlyph writes a fully attributed glyph at (x, y).
+func (tb *TermBuffer) SetGlyph(x, y int, g Glyph) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if x < 0 || y < 0 || y >= tb.Height || x >= tb.Width {
+		return
+	}
+	tb.splitWide(x, y)
+	tb.Cells[y][x] = g
+}
+
+// SetWrapped records whether row y soft-wraps onto the next row. The flag
+// lives on the row's last cell so it travels with the row when scrolling.
+func (tb *TermBuffer) SetWrapped(y int, wrapped bool) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if y < 0 || y >= tb.Height || tb.Width == 0 {
+		return
+	}
+	setLineWrapped(tb.Cells[y], wrapped)
+}
+
+// setLineWrapped sets or clears CellWrapped on the last cell of row.
+func setLineWrapped(row []Glyph, wrapped bool) {
+	if len(row) == 0 {
+		return
+	}
+	last := &row[len(row)-1]
+	if wrapped {
+		last.Flags |= CellWrapped
+	} else {
+		last.Flags &^= CellWrapped
+	}
+}
+
+// IsWrapped reports whether row y soft-wraps onto the next row.
+func (tb *TermBuffer) IsWrapped(y int) bool {
+	tb.mu.RLock()
+	defer tb.mu.RUnlock()
+	if y < 0 || y >= tb.Height {
+		return false
+	}
+	return LineWrapped(tb.Cells[y])
+}
+
+// AppendRune adds r to the grapheme cluster held at (x, y).
+func (tb *TermBuffer) AppendRune(x, y int, r rune) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if x < 0 || y < 0 || y >= tb.Height || x >= tb.Width {
+		return
+	}
+	tb.Cells[y][x].AppendRune(r)
+}
+
+// splitWide blanks the other half of a wide character about to be
+// partially overwritten at (x, y).
+func (tb *TermBuffer) splitWide(x, y int) {
+	row := tb.Cells[y]
+	switch old := row[x]; {
+	case old.IsWide() && x+1 < len(row) && row[x+1].IsWideCont():
+		row[x+1] = Glyph{Rune: ' ', Fg: old.Fg, Bg: old.Bg}
+	case old.IsWideCont() && x > 0 && row[x-1].IsWide():
+		row[x-1] = Glyph{Rune: ' ', Fg: old.Fg, Bg: old.Bg}
+	}
+}
+
 // GetRune returns a glyph at (x, y), or blank if out of bounds.
 func (tb *TermBuffer) GetRune(x, y int) Glyph {
 	tb.mu.RLock()
 	defer tb.mu.RUnlock()
 	if x < 0 || y < 0 || y >= tb.Height || x >= tb.Width {
-		return Glyph{Rune: ' ', Fg: 7, Bg: 0}
+		return Glyph{Rune: ' '}
 	}
 	return tb.Cells[y][x]
 }
@@ -95,50 +284,371 @@ func (tb *TermBuffer) ScrollUp() {
 		return
 	}
 
-	top := make([]Glyph, tb.Width)
-	copy(top, tb.Cells[0])
-
-	// Scroll up
-	copy(tb.Cells, tb.Cells[1:])
-	tb.Cells[tb.Height-1] = make([]Glyph, tb.Width)
-	for i := range tb.Cells[tb.Height-1] {
-		tb.Cells[tb.Height-1][i] = Glyph{Rune: ' ', Fg: 7, Bg: 0}
-	}
+	tb.scrollRegionUp(0, tb.Height-1, 1, true)
 
 	// Cursor safety
 	if tb.CursorY > 0 {
 		tb.CursorY--
 	}
+}
+
+// ScrollRegionUp shifts rows top..bottom (inclusive) up by n and blanks the
+// rows uncovered at the bottom of the region. Only a full-screen region
+// feeds scrollback.
+func (tb *TermBuffer) ScrollRegionUp(top, bottom, n int) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.scrollRegionUp(top, bottom, n, true)
+}
+
+// ScrollRegionDown shifts rows top..bottom (inclusive) down by n and blanks
+// the rows uncovered at the top of the region.
+func (tb *TermBuffer) ScrollRegionDown(top, bottom, n int) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.scrollRegionDown(top, bottom, n)
+}
+
+// -----------------------------------------------------------------------------
+// Line & Character Editing
+// -----------------------------------------------------------------------------
+
+// InsertLines inserts n blank rows at y, pushing rows y..bottom down.
+// Rows shifted past bottom are discarded (never sent to scrollback).
+func (tb *TermBuffer) InsertLines(y, bottom, n int) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.scrollRegionDown(y, bottom, n)
+}
+
+// DeleteLines removes n rows at y, pulling rows y..bottom up and blanking
+// the rows uncovered at bottom.
+func (tb *TermBuffer) DeleteLines(y, bottom, n int) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.scrollRegionUp(y, bottom, n, false)
+}
+
+// InsertChars shifts the cells from x to the end of row y right by n,
+// blanking the gap. Cells pushed past the right edge are lost, and so is
+// any wide character split by the insertion point or the edge. The row
+// stays wrapped if it was.
+func (tb *TermBuffer) InsertChars(x, y, n int) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if y < 0 || y >= tb.Height || x < 0 || x >= tb.Width || n <= 0 {
+		return
+	}
+	line := tb.Cells[y]
+	wrapped := LineWrapped(line)
+	setLineWrapped(line, false)
+	if line[x].IsWideCont() {
+		blankPair(line, x)
+	}
 
-	// Fire event for scrollback capture
-	if tb.bus != nil {
-		line := make([]Glyph, len(top))
-		copy(line, top)
-		go tb.bus.Publish("term_scrolled", line)
+	row := line[x:]
+	n = min(n, len(row))
+	copy(row[n:], row[:len(row)-n])
+	blankRow(row[:n])
+	if last := len(line) - 1; line[last].IsWide() {
+		line[last] = Glyph{Rune: ' ', Fg: line[last].Fg, Bg: line[last].Bg}
 	}
+	setLineWrapped(line, wrapped)
 }
 
-// Resize adjusts the terminal grid, preserving contents.
-func (tb *TermBuffer) Resize(newW, newH int) {
+// DeleteChars removes n cells at x on row y, shifting the rest of the row
+// left and blanking the cells uncovered at the right edge. A wide character
+// cut by either end of the deleted span is blanked whole. The row stays
+// wrapped if it was.
+func (tb *TermBuffer) DeleteChars(x, y, n int) {
 	tb.mu.Lock()
 	defer tb.mu.Unlock()
 
-	newCells := make([][]Glyph, newH)
-	for y := 0; y < newH; y++ {
-		newCells[y] = make([]Glyph, newW)
-		if y < tb.Height {
-			copy(newCells[y], tb.Cells[y][:min(newW, tb.Width)])
+	if y < 0 || y >= tb.Height || x < 0 || x >= tb.Width || n <= 0 {
+		return
+	}
+	line := tb.Cells[y]
+	wrapped := LineWrapped(line)
+	setLineWrapped(line, false)
+	row := line[x:]
+	n = min(n, len(row))
+	if row[0].IsWideCont() {
+		blankPair(line, x)
+	}
+	if n < len(row) && row[n].IsWideCont() {
+		blankPair(line, x+n)
+	}
+
+	copy(row, row[n:])
+	blankRow(row[len(row)-n:])
+	setLineWrapped(line, wrapped)
+}
+
+// blankPair blanks both halves of the wide character covering x.
+func blankPair(row []Glyph, x int) {
+	if row[x].IsWideCont() && x > 0 {
+		x--
+	}
+	end := x + 1
+	if end < len(row) && row[end].IsWideCont() {
+		end++
+	}
+	for i := x; i < end; i++ {
+		row[i] = Glyph{Rune: ' ', Fg: row[i].Fg, Bg: row[i].Bg}
+	}
+}
+
+func (tb *TermBuffer) scrollRegionDown(top, bottom, n int) {
+	if !tb.validRegion(top, bottom) || n <= 0 {
+		return
+	}
+	rows := tb.Cells[top : bottom+1]
+	n = min(n, len(rows))
+
+	removed := append([][]Glyph(nil), rows[len(rows)-n:]...)
+	copy(rows[n:], rows[:len(rows)-n])
+	for i, row := range removed {
+		blankRow(row)
+		rows[i] = row
+	}
+}
+
+func (tb *TermBuffer) scrollRegionUp(top, bottom, n int, capture bool) {
+	if !tb.validRegion(top, bottom) || n <= 0 {
+		return
+	}
+	rows := tb.Cells[top : bottom+1]
+	n = min(n, len(rows))
+
+	// Fire event for scrollback capture (never from the alternate screen)
+	if capture && !tb.altActive && top == 0 && bottom == tb.Height-1 {
+		tb.publishScrolled(rows[:n])
+	}
+
+	removed := append([][]Glyph(nil), rows[:n]...)
+	copy(rows, rows[n:])
+	for i, row := range removed {
+		blankRow(row)
+		rows[len(rows)-n+i] = row
+	}
+}
+
+// publishScrolled hands copies of rows to scrollback, in order: straight
+// into the attached Scrollback, or else on the bus. Rows sent on the bus are
+// out of Collect's sight, so their cluster and link ids may be reused.
+func (tb *TermBuffer) publishScrolled(rows [][]Glyph) {
+	if tb.scrollback != nil {
+		for _, row := range rows {
+			tb.scrollback.PushLine(row)
+		}
+		return
+	}
+	if tb.bus == nil || len(rows) == 0 {
+		return
+	}
+	lines := make([][]Glyph, len(rows))
+	for i := range lines {
+		lines[i] = make([]Glyph, len(rows[i]))
+		copy(lines[i], rows[i])
+	}
+	go func() {
+		for _, line := range lines {
+			tb.bus.Publish("term_scrolled", line)
 		}
+	}()
+}
+
+func (tb *TermBuffer) validRegion(top, bottom int) bool {
+	return top >= 0 && bottom < tb.Height && top <= bottom && tb.Width > 0
+}
+
+func blankRow(row []Glyph) {
+	for x := range row {
+		row[x] = Glyph{Rune: ' '}
+	}
+}
+
+// Resize adjusts both terminal grids. The primary screen is re-flowed so
+// soft-wrapped lines follow the new width and its cursor stays on the same
+// logical cell; rows pushed off the top go to scrollback. The alternate
+// screen is truncated or padded, as full-screen programs redraw anyway.
+// An attached scrollback is re-flowed along with the screen, and a line
+// wrapped across the boundary between them is re-wrapped as one.
+func (tb *TermBuffer) Resize(newW, newH int) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if newW <= 0 || newH <= 0 {
+		return
 	}
 
+	// While the alternate screen is up, the primary cursor is the saved one.
+	cx, cy := tb.CursorX, tb.CursorY
+	if tb.altActive {
+		cx, cy = tb.savedX, tb.savedY
+	}
+	grid := tb.primary
+	if head := tb.scrollback.takeWrappedTail(); len(head) > 0 {
+		grid = append(head, grid...)
+		cy += len(head)
+	}
+	primary, spill, cx, cy := reflowGrid(grid, newW, newH, cx, cy)
+	tb.primary = primary
+	tb.alternate = resizeGrid(tb.alternate, tb.Width, newW, newH)
+	tb.scrollback.Reflow(newW)
+	tb.publishScrolled(spill)
+
+	tb.tabStops = resizeTabStops(tb.tabStops, newW)
+
 	tb.Width, tb.Height = newW, newH
-	tb.Cells = newCells
+	if tb.altActive {
+		tb.Cells = tb.alternate
+		tb.savedX, tb.savedY = cx, cy
+		tb.CursorX = min(tb.CursorX, newW-1)
+		tb.CursorY = min(tb.CursorY, newH-1)
+	} else {
+		tb.Cells = tb.primary
+		tb.CursorX, tb.CursorY = cx, cy
+	}
+}
+
+// resizeGrid copies grid into a newW×newH grid, truncating or padding rows.
+func resizeGrid(grid [][]Glyph, oldW, newW, newH int) [][]Glyph {
+	newCells := newGrid(newW, newH)
+	for y := 0; y < newH && y < len(grid); y++ {
+		copy(newCells[y], grid[y][:min(newW, oldW)])
+	}
+	return newCells
+}
+
+// -----------------------------------------------------------------------------
+// Tab Stops
+// -----------------------------------------------------------------------------
 
-	if tb.CursorY >= newH {
-		tb.CursorY = newH - 1
+// tabWidth is the default distance between tab stops.
+const tabWidth = 8
+
+// resizeTabStops keeps the stops of columns that still exist and places
+// default stops every tabWidth columns in any new ones.
+func resizeTabStops(old []bool, width int) []bool {
+	stops := make([]bool, width)
+	copy(stops, old)
+	for x := len(old); x < width; x++ {
+		stops[x] = x > 0 && x%tabWidth == 0
+	}
+	return stops
+}
+
+// SetTabStop sets a tab stop at column x (HTS).
+func (tb *TermBuffer) SetTabStop(x int) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if x >= 0 && x < len(tb.tabStops) {
+		tb.tabStops[x] = true
+	}
+}
+
+// ClearTabStop removes the tab stop at column x (TBC 0).
+func (tb *TermBuffer) ClearTabStop(x int) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if x >= 0 && x < len(tb.tabStops) {
+		tb.tabStops[x] = false
+	}
+}
+
+// ClearAllTabStops removes every tab stop (TBC 3).
+func (tb *TermBuffer) ClearAllTabStops() {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	for x := range tb.tabStops {
+		tb.tabStops[x] = false
+	}
+}
+
+// NextTabStop returns the column of the n-th tab stop right of x, or the
+// last column if there are not that many.
+func (tb *TermBuffer) NextTabStop(x, n int) int {
+	tb.mu.RLock()
+	defer tb.mu.RUnlock()
+	for ; n > 0 && x < tb.Width-1; n-- {
+		x++
+		for x < tb.Width-1 && !tb.tabStops[x] {
+			x++
+		}
+	}
+	return x
+}
+
+// PrevTabStop returns the column of the n-th tab stop left of x, or column 0
+// if there are not that many.
+func (tb *TermBuffer) PrevTabStop(x, n int) int {
+	tb.mu.RLock()
+	defer tb.mu.RUnlock()
+	x = min(x, tb.Width-1)
+	for ; n > 0 && x > 0; n-- {
+		x--
+		for x > 0 && !tb.tabStops[x] {
+			x--
+		}
+	}
+	return x
+}
+
+// -----------------------------------------------------------------------------
+// Alternate Screen
+// -----------------------------------------------------------------------------
+
+// EnterAltScreen switches to the alternate grid. When saveCursor is set the
+// current cursor is remembered for ExitAltScreen; when clear is set the
+// alternate grid is blanked first.
+func (tb *TermBuffer) EnterAltScreen(saveCursor, clear bool) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if saveCursor {
+		tb.savedX, tb.savedY = tb.CursorX, tb.CursorY
 	}
-	if tb.CursorX >= newW {
-		tb.CursorX = newW - 1
+	if tb.altActive {
+		return
+	}
+	tb.altActive = true
+	tb.Cells = tb.alternate
+	if clear {
+		tb.clearGrid(tb.alternate)
+	}
+}
+
+// ExitAltScreen switches back to the primary grid. When clear is set the
+// alternate grid is blanked before leaving; when restoreCursor is set the
+// cursor saved by EnterAltScreen is restored.
+func (tb *TermBuffer) ExitAltScreen(restoreCursor, clear bool) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if tb.altActive {
+		if clear {
+			tb.clearGrid(tb.alternate)
+		}
+		tb.altActive = false
+		tb.Cells = tb.primary
+	}
+	if restoreCursor {
+		tb.CursorX, tb.CursorY = tb.savedX, tb.savedY
+	}
+}
+
+// AltScreenActive reports whether the alternate grid is being displayed.
+func (tb *TermBuffer) AltScreenActive() bool {
+	tb.mu.RLock()
+	defer tb.mu.RUnlock()
+	return tb.altActive
+}
+
+func (tb *TermBuffer) clearGrid(grid [][]Glyph) {
+	for _, row := range grid {
+		blankRow(row)
 	}
 }
 
@@ -156,9 +666,9 @@ func (tb *TermBuffer) GetCursor() (int, int) {
 }
 
 // Locking exposure (for advanced systems only)
-func (tb *TermBuffer) Lock()   { tb.mu.Lock() }
-func (tb *TermBuffer) Unlock() { tb.mu.Unlock() }
-func (tb *TermBuffer) RLock()  { tb.mu.RLock() }
+func (tb *TermBuffer) Lock()    { tb.mu.Lock() }
+func (tb *TermBuffer) Unlock()  { tb.mu.Unlock() }
+func (tb *TermBuffer) RLock()   { tb.mu.RLock() }
 func (tb *TermBuffer) RUnlock() { tb.mu.RUnlock() }
 
 func min(a, b int) int {
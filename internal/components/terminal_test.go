@@ -0,0 +1,110 @@
+package components
+
+import "testing"
+
+// wideRow fills row y of tb from cells, where 'W' is a wide character
+// (leading and continuation cell) and any other rune a narrow one.
+func wideRow(tb *TermBuffer, y int, cells string) {
+	x := 0
+	for _, r := range cells {
+		if r == 'W' {
+			tb.SetGlyph(x, y, Glyph{Rune: '中', Flags: CellWide})
+			tb.SetGlyph(x+1, y, Glyph{Flags: CellWideCont})
+			x += 2
+			continue
+		}
+		tb.SetGlyph(x, y, Glyph{Rune: r})
+		x++
+	}
+}
+
+// rowLayout renders row y back in wideRow notation, with '>' for an
+// orphaned continuation cell and '<' for a wide cell missing its other half.
+func rowLayout(tb *TermBuffer, y int) string {
+	var out []rune
+	row := tb.Cells[y]
+	for x := 0; x < len(row); x++ {
+		switch g := row[x]; {
+		case g.IsWide() && x+1 < len(row) && row[x+1].IsWideCont():
+			out = append(out, 'W')
+			x++
+		case g.IsWide():
+			out = append(out, '<')
+		case g.IsWideCont():
+			out = append(out, '>')
+		default:
+			out = append(out, g.Rune)
+		}
+	}
+	return string(out)
+}
+
+func TestInsertCharsWide(t *testing.T) {
+	tests := []struct {
+		name, row string
+		x, n      int
+		want      string
+	}{
+		{"before a pair", "aWbc", 1, 1, "a Wb"},
+		{"inside a pair", "aWbc", 2, 1, "a   b"},
+		{"pair pushed to the edge", "abWcd", 0, 2, "  ab "},
+		{"pair pushed off", "abcWd", 0, 3, "   ab"},
+	}
+	for _, tt := range tests {
+		tb := NewTermBuffer(5, 1)
+		wideRow(tb, 0, tt.row)
+		tb.InsertChars(tt.x, 0, tt.n)
+		if got := rowLayout(tb, 0); got != tt.want {
+			t.Errorf("%s: row = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDeleteCharsWide(t *testing.T) {
+	tests := []struct {
+		name, row string
+		x, n      int
+		want      string
+	}{
+		{"whole pair", "aWbc", 1, 2, "abc  "},
+		{"at the continuation", "aWbc", 2, 1, "a bc "},
+		{"leading half only", "aWbc", 1, 1, "a bc "},
+		{"pair after the span", "abWc", 1, 1, "aWc "},
+	}
+	for _, tt := range tests {
+		tb := NewTermBuffer(5, 1)
+		wideRow(tb, 0, tt.row)
+		tb.DeleteChars(tt.x, 0, tt.n)
+		if got := rowLayout(tb, 0); got != tt.want {
+			t.Errorf("%s: row = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCharEditingKeepsWrapFlag(t *testing.T) {
+	for _, wrapped := range []bool{true, false} {
+		tb := NewTermBuffer(5, 1)
+		wideRow(tb, 0, "abcde")
+		tb.SetWrapped(0, wrapped)
+
+		tb.InsertChars(1, 0, 2)
+		if tb.IsWrapped(0) != wrapped {
+			t.Errorf("InsertChars: wrapped = %v, want %v", !wrapped, wrapped)
+		}
+		for x := 0; x < 4; x++ {
+			if tb.Cells[0][x].Flags&CellWrapped != 0 {
+				t.Errorf("InsertChars left the wrap flag on column %d", x)
+			}
+		}
+
+		tb.DeleteChars(0, 0, 3)
+		if tb.IsWrapped(0) != wrapped {
+			t.Errorf("DeleteChars: wrapped = %v, want %v", !wrapped, wrapped)
+		}
+		for x := 0; x < 4; x++ {
+			if tb.Cells[0][x].Flags&CellWrapped != 0 {
+				t.Errorf("DeleteChars moved the wrap flag to column %d", x)
+			}
+		}
+	}
+}
@@ -29,6 +29,7 @@ const (
 	PriorityScrollback = 60
 	PriorityRender     = 70
 	PrioritySelection  = 80
+	PriorityClipboard  = 85
 	PriorityCursor     = 90
 	PriorityOverlay    = 100
 )
@@ -0,0 +1,204 @@
+package clipboard
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"gost/internal/components"
+	"gost/internal/ecs"
+	"gost/internal/events"
+	"gost/internal/systems/config"
+	"gost/internal/util"
+)
+
+// -----------------------------------------------------------------------------
+// Clipboard System — OSC 52 access policy
+// -----------------------------------------------------------------------------
+
+// System answers OSC 52 clipboard requests forwarded by the parser,
+// applying the configured allow / deny / ask policy and size limit.
+type System struct {
+	bus    *events.Bus
+	mu     sync.RWMutex
+	policy config.ClipboardConfig
+	clip   selections // the system clipboard; swapped out in tests
+}
+
+// selections is the clipboard OSC 52 stores into and answers from.
+type selections interface {
+	SetSelectionString(sel util.Selection, text string)
+	SelectionString(sel util.Selection) string
+}
+
+// systemSelections reaches the real clipboard through util.
+type systemSelections struct{}
+
+func (systemSelections) SetSelectionString(sel util.Selection, text string) {
+	util.SetSelectionString(sel, text)
+}
+
+func (systemSelections) SelectionString(sel util.Selection) string {
+	return util.SelectionString(sel)
+}
+
+// NewSystem creates the clipboard system with the default policy until the
+// configuration is announced.
+func NewSystem(bus *events.Bus) *System {
+	s := &System{
+		bus:    bus,
+		policy: config.DefaultConfig().Clipboard,
+		clip:   systemSelections{},
+	}
+	s.subscribeEvents()
+	return s
+}
+
+// UpdateECS is a no-op (event-driven).
+func (s *System) UpdateECS() {}
+
+// -----------------------------------------------------------------------------
+// Event Subscriptions
+// -----------------------------------------------------------------------------
+
+func (s *System) subscribeEvents() {
+	if s.bus == nil {
+		return
+	}
+	loadedSub := s.bus.Subscribe("config_loaded")
+	changedSub := s.bus.Subscribe("config_changed")
+	requestSub := s.bus.Subscribe("osc52_request")
+
+	go func() {
+		for evt := range loadedSub {
+			s.applyConfig(evt)
+		}
+	}()
+	go func() {
+		for evt := range changedSub {
+			s.applyConfig(evt)
+		}
+	}()
+	go func() {
+		for evt := range requestSub {
+			if req, ok := evt.(components.ClipboardRequest); ok {
+				s.handle(req)
+			}
+		}
+	}()
+}
+
+// applyConfig takes the policy from a configuration event. Settings missing
+// from older config files keep their defaults.
+func (s *System) applyConfig(evt events.Event) {
+	cfg, ok := evt.(*config.RootConfig)
+	if !ok {
+		return
+	}
+	p := cfg.Clipboard
+	def := config.DefaultConfig().Clipboard
+	if p.Write == "" {
+		p.Write = def.Write
+	}
+	if p.Read == "" {
+		p.Read = def.Read
+	}
+	if p.MaxBytes <= 0 {
+		p.MaxBytes = def.MaxBytes
+	}
+	s.mu.Lock()
+	s.policy = p
+	s.mu.Unlock()
+}
+
+// Policy returns the active OSC 52 policy.
+func (s *System) Policy() config.ClipboardConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.policy
+}
+
+// -----------------------------------------------------------------------------
+// Requests
+// -----------------------------------------------------------------------------
+
+func (s *System) handle(req components.ClipboardRequest) {
+	p := s.Policy()
+	if req.IsQuery() {
+		s.read(req, p)
+	} else {
+		s.write(req, p)
+	}
+}
+
+// write stores decoded OSC 52 text in the clipboard.
+func (s *System) write(req components.ClipboardRequest, p config.ClipboardConfig) {
+	data, err := base64.StdEncoding.DecodeString(req.Data)
+	if err != nil {
+		log.Println("[Clipboard] OSC 52 write: bad base64:", err)
+		return
+	}
+	if len(data) > p.MaxBytes {
+		log.Printf("[Clipboard] OSC 52 write refused: %d bytes over the %d byte limit", len(data), p.MaxBytes)
+		return
+	}
+	text := string(data)
+	s.decide(p.Write, fmt.Sprintf("A program wants to set the clipboard (%d bytes). Allow?", len(data)), func() {
+		for _, sel := range targets(req.Selection) {
+			s.clip.SetSelectionString(sel, text)
+		}
+	})
+}
+
+// targets maps OSC 52 selection characters to clipboards: 'p' is PRIMARY,
+// everything else ('c', 's', cut buffers, or none) is CLIPBOARD.
+func targets(pc string) []util.Selection {
+	var sels []util.Selection
+	if strings.ContainsRune(pc, 'p') {
+		sels = append(sels, util.Primary)
+	}
+	if pc == "" || strings.Trim(pc, "p") != "" {
+		sels = append(sels, util.Clipboard)
+	}
+	return sels
+}
+
+// read answers an OSC 52 query with the clipboard contents in base64.
+func (s *System) read(req components.ClipboardRequest, p config.ClipboardConfig) {
+	s.decide(p.Read, "A program wants to read the clipboard. Allow?", func() {
+		text := s.clip.SelectionString(targets(req.Selection)[0])
+		if len(text) > p.MaxBytes {
+			log.Printf("[Clipboard] OSC 52 read refused: %d bytes over the %d byte limit", len(text), p.MaxBytes)
+			return
+		}
+		reply := "\x1b]52;" + req.Selection + ";" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x1b\\"
+		s.bus.Publish("pty_write", []byte(reply))
+	})
+}
+
+// decide runs apply when policy allows it, asking through the overlay for
+// "ask". Anything else denies.
+func (s *System) decide(policy, question string, apply func()) {
+	switch policy {
+	case config.PolicyAllow:
+		apply()
+	case config.PolicyAsk:
+		s.bus.Publish("prompt_request", components.Prompt{
+			Text: question,
+			Answer: func(ok bool) {
+				if ok {
+					apply()
+				} else {
+					log.Println("[Clipboard] OSC 52 request declined")
+				}
+			},
+		})
+	default:
+		log.Println("[Clipboard] OSC 52 request denied by policy")
+	}
+}
+
+// ECS compliance
+var _ ecs.System = (*System)(nil)
@@ -0,0 +1,241 @@
+package clipboard
+
+import (
+	"encoding/base64"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gost/internal/components"
+	"gost/internal/events"
+	"gost/internal/systems/config"
+	"gost/internal/util"
+)
+
+// fakeSelections is an in-memory clipboard.
+type fakeSelections struct {
+	mu   sync.Mutex
+	sels map[util.Selection]string
+}
+
+func newFakeSelections() *fakeSelections {
+	return &fakeSelections{sels: make(map[util.Selection]string)}
+}
+
+func (f *fakeSelections) SetSelectionString(sel util.Selection, text string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sels[sel] = text
+}
+
+func (f *fakeSelections) SelectionString(sel util.Selection) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sels[sel]
+}
+
+// testSystem returns a system with the given policy and a fake clipboard.
+// Prompts are answered with answer as soon as they are published.
+func testSystem(t *testing.T, p config.ClipboardConfig, answer bool) (*System, *fakeSelections, <-chan events.Event) {
+	t.Helper()
+	bus := events.NewBus()
+	replies := bus.Subscribe("pty_write")
+	prompts := bus.Subscribe("prompt_request")
+	go func() {
+		for evt := range prompts {
+			evt.(components.Prompt).Answer(answer)
+		}
+	}()
+	t.Cleanup(bus.Close)
+
+	clip := newFakeSelections()
+	return &System{bus: bus, policy: p, clip: clip}, clip, replies
+}
+
+func policy(write, read string) config.ClipboardConfig {
+	return config.ClipboardConfig{Write: write, Read: read, MaxBytes: 16}
+}
+
+func b64(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+
+// waitFor polls cond until it holds or wait has passed.
+func waitFor(wait time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(wait)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return true
+}
+
+// reply returns the next pty_write reply, or "" if none comes.
+func reply(ch <-chan events.Event, wait time.Duration) string {
+	select {
+	case r := <-ch:
+		return string(r.([]byte))
+	case <-time.After(wait):
+		return ""
+	}
+}
+
+func TestWritePolicy(t *testing.T) {
+	tests := []struct {
+		policy string
+		answer bool
+		stored bool
+	}{
+		{config.PolicyAllow, false, true},
+		{config.PolicyDeny, true, false},
+		{config.PolicyAsk, true, true},
+		{config.PolicyAsk, false, false},
+		{"bogus", true, false},
+	}
+	for _, tt := range tests {
+		s, clip, _ := testSystem(t, policy(tt.policy, config.PolicyDeny), tt.answer)
+		s.handle(components.ClipboardRequest{Selection: "c", Data: b64("hello")})
+
+		wait := time.Second
+		if !tt.stored {
+			wait = 50 * time.Millisecond
+		}
+		stored := waitFor(wait, func() bool { return clip.SelectionString(util.Clipboard) == "hello" })
+		if stored != tt.stored {
+			t.Errorf("%s (answer %v): stored = %v, want %v", tt.policy, tt.answer, stored, tt.stored)
+		}
+	}
+}
+
+func TestReadPolicy(t *testing.T) {
+	tests := []struct {
+		policy  string
+		answer  bool
+		replied bool
+	}{
+		{config.PolicyAllow, false, true},
+		{config.PolicyDeny, true, false},
+		{config.PolicyAsk, true, true},
+		{config.PolicyAsk, false, false},
+	}
+	for _, tt := range tests {
+		s, clip, replies := testSystem(t, policy(config.PolicyDeny, tt.policy), tt.answer)
+		clip.SetSelectionString(util.Clipboard, "secret")
+		s.handle(components.ClipboardRequest{Selection: "c", Data: "?"})
+
+		wait := time.Second
+		if !tt.replied {
+			wait = 50 * time.Millisecond
+		}
+		got := reply(replies, wait)
+		want := ""
+		if tt.replied {
+			want = "\x1b]52;c;" + b64("secret") + "\x1b\\"
+		}
+		if got != want {
+			t.Errorf("%s (answer %v): reply = %q, want %q", tt.policy, tt.answer, got, want)
+		}
+	}
+}
+
+func TestMaxBytes(t *testing.T) {
+	s, clip, replies := testSystem(t, policy(config.PolicyAllow, config.PolicyAllow), false)
+
+	s.handle(components.ClipboardRequest{Selection: "c", Data: b64(strings.Repeat("x", 17))})
+	if got := clip.SelectionString(util.Clipboard); got != "" {
+		t.Fatalf("write over the limit stored %d bytes", len(got))
+	}
+	s.handle(components.ClipboardRequest{Selection: "c", Data: b64(strings.Repeat("x", 16))})
+	if got := clip.SelectionString(util.Clipboard); len(got) != 16 {
+		t.Fatalf("write at the limit stored %d bytes", len(got))
+	}
+
+	clip.SetSelectionString(util.Clipboard, strings.Repeat("y", 17))
+	s.handle(components.ClipboardRequest{Selection: "c", Data: "?"})
+	if got := reply(replies, 50*time.Millisecond); got != "" {
+		t.Fatalf("read over the limit replied %q", got)
+	}
+}
+
+func TestBadBase64(t *testing.T) {
+	s, clip, _ := testSystem(t, policy(config.PolicyAllow, config.PolicyAllow), false)
+	clip.SetSelectionString(util.Clipboard, "kept")
+	s.handle(components.ClipboardRequest{Selection: "c", Data: "not base64!"})
+	if got := clip.SelectionString(util.Clipboard); got != "kept" {
+		t.Fatalf("bad base64 replaced the clipboard with %q", got)
+	}
+}
+
+func TestSelectionTargets(t *testing.T) {
+	tests := []struct {
+		pc                 string
+		clipboard, primary bool
+	}{
+		{"", true, false},
+		{"c", true, false},
+		{"s", true, false},
+		{"0", true, false},
+		{"p", false, true},
+		{"pc", true, true},
+		{"s0", true, false},
+	}
+	for _, tt := range tests {
+		s, clip, _ := testSystem(t, policy(config.PolicyAllow, config.PolicyAllow), false)
+		s.handle(components.ClipboardRequest{Selection: tt.pc, Data: b64("v")})
+		if got := clip.SelectionString(util.Clipboard) == "v"; got != tt.clipboard {
+			t.Errorf("%q: CLIPBOARD set = %v, want %v", tt.pc, got, tt.clipboard)
+		}
+		if got := clip.SelectionString(util.Primary) == "v"; got != tt.primary {
+			t.Errorf("%q: PRIMARY set = %v, want %v", tt.pc, got, tt.primary)
+		}
+	}
+}
+
+func TestQueryReadsTargetSelection(t *testing.T) {
+	s, clip, replies := testSystem(t, policy(config.PolicyAllow, config.PolicyAllow), false)
+	clip.SetSelectionString(util.Clipboard, "clip")
+	clip.SetSelectionString(util.Primary, "prim")
+
+	s.handle(components.ClipboardRequest{Selection: "p", Data: "?"})
+	if got, want := reply(replies, time.Second), "\x1b]52;p;"+b64("prim")+"\x1b\\"; got != want {
+		t.Fatalf("PRIMARY query reply = %q, want %q", got, want)
+	}
+	s.handle(components.ClipboardRequest{Selection: "", Data: "?"})
+	if got, want := reply(replies, time.Second), "\x1b]52;;"+b64("clip")+"\x1b\\"; got != want {
+		t.Fatalf("default query reply = %q, want %q", got, want)
+	}
+}
+
+func TestApplyConfigKeepsDefaults(t *testing.T) {
+	s := &System{}
+	cfg := config.DefaultConfig()
+	cfg.Clipboard = config.ClipboardConfig{Read: config.PolicyAllow}
+	s.applyConfig(cfg)
+
+	def := config.DefaultConfig().Clipboard
+	p := s.Policy()
+	if p.Write != def.Write || p.Read != config.PolicyAllow || p.MaxBytes != def.MaxBytes {
+		t.Fatalf("policy = %+v", p)
+	}
+}
+
+func TestRequestsFromTheBus(t *testing.T) {
+	bus := events.NewBus()
+	defer bus.Close()
+	replies := bus.Subscribe("pty_write")
+	s := NewSystem(bus)
+	clip := newFakeSelections()
+	s.clip = clip
+
+	bus.Publish("osc52_request", components.ClipboardRequest{Selection: "c", Data: b64("hello")})
+	if !waitFor(time.Second, func() bool { return clip.SelectionString(util.Clipboard) == "hello" }) {
+		t.Fatal("OSC 52 write from the bus was not stored")
+	}
+
+	// Reads are "ask" by default; nothing answers here, so no reply.
+	bus.Publish("osc52_request", components.ClipboardRequest{Selection: "c", Data: "?"})
+	if got := reply(replies, 50*time.Millisecond); got != "" {
+		t.Fatalf("unanswered read replied %q", got)
+	}
+}
@@ -17,12 +17,14 @@ import (
 
 // RootConfig represents the persistent user configuration file.
 type RootConfig struct {
-	Version     int           `json:"version"`
-	ShellPath   string        `json:"shell_path"`
-	FontFamily  string        `json:"font_family"`
-	FontSize    int           `json:"font_size"`
-	Theme       ThemeConfig   `json:"theme"`
-	KeyBindings []KeyBinding  `json:"key_bindings,omitempty"`
+	Version     int             `json:"version"`
+	ShellPath   string          `json:"shell_path"`
+	FontFamily  string          `json:"font_family"`
+	FontSize    int             `json:"font_size"`
+	Theme       ThemeConfig     `json:"theme"`
+	KeyBindings []KeyBinding    `json:"key_bindings,omitempty"`
+	Links       LinkConfig      `json:"links"`
+	Clipboard   ClipboardConfig `json:"clipboard"`
 }
 
 // ThemeConfig defines terminal foreground/background color preferences.
@@ -33,6 +35,28 @@ type ThemeConfig struct {
 	Selection  string `json:"selection"`
 }
 
+// LinkConfig controls OSC 8 hyperlinks.
+type LinkConfig struct {
+	Opener  string   `json:"opener"`             // command run with the URI on Ctrl+click
+	Schemes []string `json:"schemes,omitempty"`  // URI schemes the opener may be given
+	CopyURL bool     `json:"copy_url,omitempty"` // copy appends " <URI>" after linked text
+}
+
+// ClipboardConfig controls program access to the clipboard through OSC 52.
+// Write and Read each take "allow", "deny" or "ask" (prompt in the overlay).
+type ClipboardConfig struct {
+	Write    string `json:"osc52_write"`
+	Read     string `json:"osc52_read"`
+	MaxBytes int    `json:"osc52_max_bytes"` // larger transfers are refused
+}
+
+// Clipboard access policies.
+const (
+	PolicyAllow = "allow"
+	PolicyDeny  = "deny"
+	PolicyAsk   = "ask"
+)
+
 // KeyBinding describes a single custom key → action mapping.
 type KeyBinding struct {
 	Key     string `json:"key"`
@@ -63,6 +87,15 @@ func DefaultConfig() *RootConfig {
 			{Key: "Q", Action: "clear_selection", Control: true, Shift: true},
 			{Key: "R", Action: "reload_config", Control: true, Shift: true},
 		},
+		Links: LinkConfig{
+			Opener:  "xdg-open",
+			Schemes: []string{"http", "https", "mailto"},
+		},
+		Clipboard: ClipboardConfig{
+			Write:    PolicyAllow,
+			Read:     PolicyAsk,
+			MaxBytes: 1 << 20,
+		},
 	}
 }
 
@@ -42,12 +42,15 @@ type System struct {
 	bus *events.Bus
 	mu  sync.RWMutex
 
-	term *components.TermBuffer
+	term         *components.TermBuffer
+	modes        *components.ModeTable // terminal modes set by the parser
 	cellW, cellH int
 
 	style        cursorStyle
 	blinkVisible bool
 	lastBlink    time.Time
+	hidden       bool // DECTCEM reset by the application
+	blinkMode    bool // last mode 12 state seen
 }
 
 // NewSystem creates a new cursor system with defaults and subscribes to events.
@@ -73,6 +76,7 @@ func (c *System) UpdateECS() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	c.pollModes()
 	if !c.style.Blink {
 		c.blinkVisible = true
 		return
@@ -88,7 +92,7 @@ func (c *System) Draw(screen *ebiten.Image) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	if c.term == nil || !c.blinkVisible {
+	if c.term == nil || !c.blinkVisible || c.hidden {
 		return
 	}
 
@@ -171,6 +175,20 @@ func (c *System) applyConfig(cfg map[string]interface{}) {
 	}
 }
 
+// pollModes follows cursor visibility (DECTCEM) and blink (mode 12) as set
+// by the running application. Mode 12 only overrides the configured blink
+// when the application changes it.
+func (c *System) pollModes() {
+	if c.modes == nil {
+		return
+	}
+	c.hidden = !c.modes.Enabled(components.ModeCursorVisible)
+	if blink := c.modes.Enabled(components.ModeCursorBlink); blink != c.blinkMode {
+		c.blinkMode = blink
+		c.style.Blink = blink
+	}
+}
+
 // -----------------------------------------------------------------------------
 // Term Buffer Integration
 // -----------------------------------------------------------------------------
@@ -197,6 +215,13 @@ func (c *System) AttachTerm(term *components.TermBuffer) {
 	c.term = term
 }
 
+// AttachModes gives the system the parser's mode table, read every frame.
+func (c *System) AttachModes(modes *components.ModeTable) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.modes = modes
+}
+
 // -----------------------------------------------------------------------------
 // Utilities
 // -----------------------------------------------------------------------------
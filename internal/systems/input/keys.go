@@ -0,0 +1,303 @@
+package input
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// -----------------------------------------------------------------------------
+// Key Encoder — xterm-compatible byte sequences
+// -----------------------------------------------------------------------------
+
+// Key identifies a non-text key. The encoder works on these rather than on
+// Ebiten keys so it can be tested without a window; map.go translates.
+type Key int
+
+const (
+	KeyUnknown Key = iota
+	KeyEnter
+	KeyTab
+	KeyBackspace
+	KeyEscape
+	KeyUp
+	KeyDown
+	KeyRight
+	KeyLeft
+	KeyHome
+	KeyEnd
+	KeyInsert
+	KeyDelete
+	KeyPageUp
+	KeyPageDown
+	KeyF1 // KeyF1+n is F(n+1), up to KeyF24
+)
+
+// KeyF24 is the last function key.
+const KeyF24 = KeyF1 + 23
+
+// Numeric keypad keys.
+const (
+	KeyKP0 Key = KeyF24 + 1 + iota
+	KeyKP1
+	KeyKP2
+	KeyKP3
+	KeyKP4
+	KeyKP5
+	KeyKP6
+	KeyKP7
+	KeyKP8
+	KeyKP9
+	KeyKPDecimal
+	KeyKPDivide
+	KeyKPMultiply
+	KeyKPSubtract
+	KeyKPAdd
+	KeyKPEnter
+	KeyKPEqual
+)
+
+// Modifier keys, reported as keys only by the kitty keyboard protocol.
+const (
+	KeyLeftShift Key = KeyKPEqual + 1 + iota
+	KeyLeftCtrl
+	KeyLeftAlt
+	KeyLeftSuper
+	KeyRightShift
+	KeyRightCtrl
+	KeyRightAlt
+	KeyRightSuper
+)
+
+// Mod is a set of modifiers, using xterm's bit values so that the
+// modifier parameter of a sequence is 1 + Mod.
+type Mod uint8
+
+const (
+	ModShift Mod = 1 << iota
+	ModAlt
+	ModCtrl
+)
+
+// KeyModes are the terminal modes that change how keys are encoded.
+type KeyModes struct {
+	AppCursor bool // DECCKM: unmodified cursor keys send SS3 instead of CSI
+	AppKeypad bool // DECKPAM: the keypad sends SS3 application codes
+}
+
+// keySeq describes how xterm encodes a special key: CSI <final>, SS3 <final>
+// or CSI <num> ~, each taking a ";<mod>" parameter when modified.
+type keySeq struct {
+	num    int  // parameter for CSI <num> ~ keys, 0 for letter finals
+	final  byte // final byte
+	ss3    bool // unmodified form is SS3 <final>
+	cursor bool // cursor key: unmodified form is SS3 <final> under DECCKM
+}
+
+var keySeqs = map[Key]keySeq{
+	KeyUp:       {final: 'A', cursor: true},
+	KeyDown:     {final: 'B', cursor: true},
+	KeyRight:    {final: 'C', cursor: true},
+	KeyLeft:     {final: 'D', cursor: true},
+	KeyHome:     {final: 'H', cursor: true},
+	KeyEnd:      {final: 'F', cursor: true},
+	KeyInsert:   {num: 2, final: '~'},
+	KeyDelete:   {num: 3, final: '~'},
+	KeyPageUp:   {num: 5, final: '~'},
+	KeyPageDown: {num: 6, final: '~'},
+	KeyF1:       {final: 'P', ss3: true},
+	KeyF1 + 1:   {final: 'Q', ss3: true},
+	KeyF1 + 2:   {final: 'R', ss3: true},
+	KeyF1 + 3:   {final: 'S', ss3: true},
+	KeyF1 + 4:   {num: 15, final: '~'},
+	KeyF1 + 5:   {num: 17, final: '~'},
+	KeyF1 + 6:   {num: 18, final: '~'},
+	KeyF1 + 7:   {num: 19, final: '~'},
+	KeyF1 + 8:   {num: 20, final: '~'},
+	KeyF1 + 9:   {num: 21, final: '~'},
+	KeyF1 + 10:  {num: 23, final: '~'},
+	KeyF1 + 11:  {num: 24, final: '~'},
+}
+
+// keypadKey is a keypad key's character in numeric mode and its SS3 final
+// in application mode.
+type keypadKey struct{ char, app byte }
+
+var keypadKeys = map[Key]keypadKey{
+	KeyKP0:        {'0', 'p'},
+	KeyKP1:        {'1', 'q'},
+	KeyKP2:        {'2', 'r'},
+	KeyKP3:        {'3', 's'},
+	KeyKP4:        {'4', 't'},
+	KeyKP5:        {'5', 'u'},
+	KeyKP6:        {'6', 'v'},
+	KeyKP7:        {'7', 'w'},
+	KeyKP8:        {'8', 'x'},
+	KeyKP9:        {'9', 'y'},
+	KeyKPDecimal:  {'.', 'n'},
+	KeyKPDivide:   {'/', 'o'},
+	KeyKPMultiply: {'*', 'j'},
+	KeyKPSubtract: {'-', 'm'},
+	KeyKPAdd:      {'+', 'k'},
+	KeyKPEnter:    {'\r', 'M'},
+	KeyKPEqual:    {'=', 'X'},
+}
+
+// EncodeKey returns the bytes xterm sends for a special key in the given
+// terminal modes, or nil for an unknown key.
+func EncodeKey(k Key, m Mod, km KeyModes) []byte {
+	switch k {
+	case KeyEnter:
+		return withAlt(m, '\r')
+	case KeyTab:
+		if m&ModShift != 0 {
+			return []byte("\x1b[Z")
+		}
+		return withAlt(m, '\t')
+	case KeyBackspace:
+		if m&ModCtrl != 0 {
+			return withAlt(m, 0x08)
+		}
+		return withAlt(m, 0x7f)
+	case KeyEscape:
+		return withAlt(m, 0x1b)
+	}
+
+	if kp, ok := keypadKeys[k]; ok {
+		if km.AppKeypad {
+			return []byte{0x1b, 'O', kp.app}
+		}
+		return withAlt(m, kp.char)
+	}
+
+	// F13–F24 are Shift+F1–F12, as in xterm's terminfo.
+	if k > KeyF1+11 && k <= KeyF24 {
+		k -= 12
+		m |= ModShift
+	}
+	seq, ok := keySeqs[k]
+	if !ok {
+		return nil
+	}
+
+	switch {
+	case m == 0 && (seq.ss3 || seq.cursor && km.AppCursor):
+		return []byte{0x1b, 'O', seq.final}
+	case m == 0 && seq.num == 0:
+		return []byte{0x1b, '[', seq.final}
+	}
+	b := []byte("\x1b[")
+	if seq.num != 0 {
+		b = strconv.AppendInt(b, int64(seq.num), 10)
+	} else {
+		b = append(b, '1')
+	}
+	if m != 0 {
+		b = append(b, ';')
+		b = strconv.AppendInt(b, int64(m)+1, 10)
+	}
+	return append(b, seq.final)
+}
+
+// EncodeRune returns the bytes for a character typed with Ctrl and/or Alt:
+// Ctrl maps to the C0 control code where one exists, and Alt prefixes ESC.
+// Callers pass the shifted character when Shift is held.
+func EncodeRune(r rune, m Mod) []byte {
+	if m&ModCtrl != 0 {
+		if c, ok := ctrlCode(r); ok {
+			return withAlt(m, c)
+		}
+	}
+	var b []byte
+	if m&ModAlt != 0 {
+		b = append(b, 0x1b)
+	}
+	return utf8.AppendRune(b, r)
+}
+
+// EncodeOtherKeys returns xterm's modifyOtherKeys form, CSI 27 ; <1+mods> ;
+// <code> ~, for a character typed with Ctrl and/or Alt, or nil when the
+// level leaves the legacy encoding in place. Level 2 encodes every such
+// chord; level 1 only Ctrl chords that lack a distinct control code (those
+// with Shift, or on keys without one).
+func EncodeOtherKeys(r rune, m Mod, level int) []byte {
+	if m&(ModCtrl|ModAlt) == 0 {
+		return nil
+	}
+	switch level {
+	case 2:
+		return otherKeySeq(r, m)
+	case 1:
+		if m&ModCtrl == 0 {
+			return nil
+		}
+		if _, ok := ctrlCode(r); ok && m&ModShift == 0 {
+			return nil
+		}
+		return otherKeySeq(r, m)
+	}
+	return nil
+}
+
+// otherKeyCodes are the codes of special keys in modifyOtherKeys sequences.
+var otherKeyCodes = map[Key]rune{
+	KeyEnter:     '\r',
+	KeyTab:       '\t',
+	KeyBackspace: 0x7f,
+	KeyEscape:    0x1b,
+}
+
+// EncodeOtherKey returns the modifyOtherKeys form of a modified Enter, Tab,
+// Backspace or Escape at level 2, whose legacy encodings drop most
+// modifiers, or nil. Shift+Tab keeps its CSI Z back-tab.
+func EncodeOtherKey(k Key, m Mod, level int) []byte {
+	code, ok := otherKeyCodes[k]
+	if !ok || level != 2 || m == 0 || k == KeyTab && m == ModShift {
+		return nil
+	}
+	return otherKeySeq(code, m)
+}
+
+func otherKeySeq(code rune, m Mod) []byte {
+	b := []byte("\x1b[27;")
+	b = strconv.AppendInt(b, int64(m)+1, 10)
+	b = append(b, ';')
+	b = strconv.AppendInt(b, int64(code), 10)
+	return append(b, '~')
+}
+
+// ctrlCode returns the C0 control code for Ctrl+r, following xterm: letters
+// and @[\]^_ map to their code minus 0x40, the digit row 2–8 doubles as
+// those punctuation keys, and ? is DEL.
+func ctrlCode(r rune) (byte, bool) {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return byte(r - 'a' + 1), true
+	case r >= '@' && r <= '_':
+		return byte(r - '@'), true
+	}
+	switch r {
+	case ' ', '2':
+		return 0x00, true
+	case '3':
+		return 0x1b, true
+	case '4':
+		return 0x1c, true
+	case '5':
+		return 0x1d, true
+	case '6', '~':
+		return 0x1e, true
+	case '7', '/', '-':
+		return 0x1f, true
+	case '8', '?':
+		return 0x7f, true
+	}
+	return 0, false
+}
+
+// withAlt returns c, prefixed with ESC when Alt is held.
+func withAlt(m Mod, c byte) []byte {
+	if m&ModAlt != 0 {
+		return []byte{0x1b, c}
+	}
+	return []byte{c}
+}
@@ -0,0 +1,244 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestEncodeKey(t *testing.T) {
+	tests := []struct {
+		key  Key
+		mod  Mod
+		want string
+	}{
+		{KeyEnter, 0, "\r"},
+		{KeyEnter, ModAlt, "\x1b\r"},
+		{KeyEnter, ModShift, "\r"},
+		{KeyTab, 0, "\t"},
+		{KeyTab, ModShift, "\x1b[Z"},
+		{KeyTab, ModAlt, "\x1b\t"},
+		{KeyBackspace, 0, "\x7f"},
+		{KeyBackspace, ModCtrl, "\x08"},
+		{KeyBackspace, ModAlt, "\x1b\x7f"},
+		{KeyEscape, 0, "\x1b"},
+		{KeyEscape, ModAlt, "\x1b\x1b"},
+
+		{KeyUp, 0, "\x1b[A"},
+		{KeyDown, 0, "\x1b[B"},
+		{KeyRight, 0, "\x1b[C"},
+		{KeyLeft, 0, "\x1b[D"},
+		{KeyHome, 0, "\x1b[H"},
+		{KeyEnd, 0, "\x1b[F"},
+		{KeyUp, ModShift, "\x1b[1;2A"},
+		{KeyLeft, ModAlt, "\x1b[1;3D"},
+		{KeyRight, ModCtrl, "\x1b[1;5C"},
+		{KeyHome, ModCtrl | ModShift, "\x1b[1;6H"},
+		{KeyEnd, ModCtrl | ModAlt, "\x1b[1;7F"},
+		{KeyDown, ModCtrl | ModAlt | ModShift, "\x1b[1;8B"},
+
+		{KeyInsert, 0, "\x1b[2~"},
+		{KeyDelete, 0, "\x1b[3~"},
+		{KeyPageUp, 0, "\x1b[5~"},
+		{KeyPageDown, 0, "\x1b[6~"},
+		{KeyDelete, ModCtrl, "\x1b[3;5~"},
+		{KeyPageUp, ModShift, "\x1b[5;2~"},
+
+		{KeyF1, 0, "\x1bOP"},
+		{KeyF1 + 1, 0, "\x1bOQ"},
+		{KeyF1 + 2, 0, "\x1bOR"},
+		{KeyF1 + 3, 0, "\x1bOS"},
+		{KeyF1 + 4, 0, "\x1b[15~"},
+		{KeyF1 + 5, 0, "\x1b[17~"},
+		{KeyF1 + 6, 0, "\x1b[18~"},
+		{KeyF1 + 7, 0, "\x1b[19~"},
+		{KeyF1 + 8, 0, "\x1b[20~"},
+		{KeyF1 + 9, 0, "\x1b[21~"},
+		{KeyF1 + 10, 0, "\x1b[23~"},
+		{KeyF1 + 11, 0, "\x1b[24~"},
+		{KeyF1, ModCtrl, "\x1b[1;5P"},
+		{KeyF1 + 3, ModShift, "\x1b[1;2S"},
+		{KeyF1 + 4, ModAlt, "\x1b[15;3~"},
+		{KeyF1 + 11, ModCtrl | ModShift, "\x1b[24;6~"},
+
+		{KeyF1 + 12, 0, "\x1b[1;2P"},
+		{KeyF1 + 15, 0, "\x1b[1;2S"},
+		{KeyF1 + 16, 0, "\x1b[15;2~"},
+		{KeyF24, 0, "\x1b[24;2~"},
+		{KeyF1 + 12, ModCtrl, "\x1b[1;6P"},
+
+		{KeyUnknown, 0, ""},
+	}
+	for _, tt := range tests {
+		if got := string(EncodeKey(tt.key, tt.mod, KeyModes{})); got != tt.want {
+			t.Errorf("EncodeKey(%d, %d) = %q, want %q", tt.key, tt.mod, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeKeyModes(t *testing.T) {
+	appCursor := KeyModes{AppCursor: true}
+	appKeypad := KeyModes{AppKeypad: true}
+	tests := []struct {
+		key  Key
+		mod  Mod
+		km   KeyModes
+		want string
+	}{
+		{KeyUp, 0, appCursor, "\x1bOA"},
+		{KeyDown, 0, appCursor, "\x1bOB"},
+		{KeyRight, 0, appCursor, "\x1bOC"},
+		{KeyLeft, 0, appCursor, "\x1bOD"},
+		{KeyHome, 0, appCursor, "\x1bOH"},
+		{KeyEnd, 0, appCursor, "\x1bOF"},
+		{KeyUp, ModCtrl, appCursor, "\x1b[1;5A"}, // modified keys keep CSI
+		{KeyDelete, 0, appCursor, "\x1b[3~"},
+		{KeyF1 + 4, 0, appCursor, "\x1b[15~"},
+
+		{KeyKP0, 0, KeyModes{}, "0"},
+		{KeyKP9, 0, KeyModes{}, "9"},
+		{KeyKPDecimal, 0, KeyModes{}, "."},
+		{KeyKPEnter, 0, KeyModes{}, "\r"},
+		{KeyKPAdd, ModAlt, KeyModes{}, "\x1b+"},
+		{KeyUp, 0, appKeypad, "\x1b[A"}, // DECKPAM leaves cursor keys alone
+
+		{KeyKP0, 0, appKeypad, "\x1bOp"},
+		{KeyKP1, 0, appKeypad, "\x1bOq"},
+		{KeyKP5, 0, appKeypad, "\x1bOu"},
+		{KeyKP9, 0, appKeypad, "\x1bOy"},
+		{KeyKPDecimal, 0, appKeypad, "\x1bOn"},
+		{KeyKPDivide, 0, appKeypad, "\x1bOo"},
+		{KeyKPMultiply, 0, appKeypad, "\x1bOj"},
+		{KeyKPSubtract, 0, appKeypad, "\x1bOm"},
+		{KeyKPAdd, 0, appKeypad, "\x1bOk"},
+		{KeyKPEnter, 0, appKeypad, "\x1bOM"},
+		{KeyKPEqual, 0, appKeypad, "\x1bOX"},
+	}
+	for _, tt := range tests {
+		if got := string(EncodeKey(tt.key, tt.mod, tt.km)); got != tt.want {
+			t.Errorf("EncodeKey(%d, %d, %+v) = %q, want %q", tt.key, tt.mod, tt.km, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeRuneCtrl(t *testing.T) {
+	for r := 'a'; r <= 'z'; r++ {
+		want := string(rune(r - 'a' + 1))
+		if got := string(EncodeRune(r, ModCtrl)); got != want {
+			t.Errorf("Ctrl+%c = %q, want %q", r, got, want)
+		}
+		if got := string(EncodeRune(r-32, ModCtrl|ModShift)); got != want {
+			t.Errorf("Ctrl+Shift+%c = %q, want %q", r-32, got, want)
+		}
+	}
+
+	tests := []struct {
+		r    rune
+		want byte
+	}{
+		{'@', 0x00}, {' ', 0x00}, {'2', 0x00},
+		{'[', 0x1b}, {'3', 0x1b},
+		{'\\', 0x1c}, {'4', 0x1c},
+		{']', 0x1d}, {'5', 0x1d},
+		{'^', 0x1e}, {'6', 0x1e}, {'~', 0x1e},
+		{'_', 0x1f}, {'7', 0x1f}, {'/', 0x1f}, {'-', 0x1f},
+		{'?', 0x7f}, {'8', 0x7f},
+	}
+	for _, tt := range tests {
+		if got := EncodeRune(tt.r, ModCtrl); len(got) != 1 || got[0] != tt.want {
+			t.Errorf("Ctrl+%q = %q, want %q", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeRuneAlt(t *testing.T) {
+	tests := []struct {
+		r    rune
+		mod  Mod
+		want string
+	}{
+		{'b', ModAlt, "\x1bb"},
+		{'B', ModAlt | ModShift, "\x1bB"},
+		{'.', ModAlt, "\x1b."},
+		{'é', ModAlt, "\x1bé"},
+		{'c', ModAlt | ModCtrl, "\x1b\x03"},
+		{'1', ModCtrl, "1"}, // no control code: sent as typed
+		{'=', ModCtrl | ModAlt, "\x1b="},
+	}
+	for _, tt := range tests {
+		if got := string(EncodeRune(tt.r, tt.mod)); got != tt.want {
+			t.Errorf("EncodeRune(%q, %d) = %q, want %q", tt.r, tt.mod, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeOtherKeys(t *testing.T) {
+	tests := []struct {
+		r     rune
+		mod   Mod
+		level int
+		want  string
+	}{
+		{'a', ModCtrl, 0, ""},
+		{'a', ModCtrl, 1, ""}, // Ctrl+A has its own control code
+		{'A', ModCtrl | ModShift, 1, "\x1b[27;6;65~"},
+		{'1', ModCtrl, 1, "\x1b[27;5;49~"},
+		{';', ModCtrl, 1, "\x1b[27;5;59~"},
+		{'b', ModAlt, 1, ""},
+		{'a', ModCtrl, 2, "\x1b[27;5;97~"},
+		{'A', ModCtrl | ModShift, 2, "\x1b[27;6;65~"},
+		{'b', ModAlt, 2, "\x1b[27;3;98~"},
+		{'.', ModCtrl | ModAlt, 2, "\x1b[27;7;46~"},
+		{'a', ModShift, 2, ""}, // plain text
+		{'a', 0, 2, ""},
+	}
+	for _, tt := range tests {
+		if got := string(EncodeOtherKeys(tt.r, tt.mod, tt.level)); got != tt.want {
+			t.Errorf("EncodeOtherKeys(%q, %d, %d) = %q, want %q", tt.r, tt.mod, tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeOtherKey(t *testing.T) {
+	tests := []struct {
+		key   Key
+		mod   Mod
+		level int
+		want  string
+	}{
+		{KeyEnter, ModCtrl, 2, "\x1b[27;5;13~"},
+		{KeyEnter, ModShift, 2, "\x1b[27;2;13~"},
+		{KeyTab, ModCtrl, 2, "\x1b[27;5;9~"},
+		{KeyTab, ModShift, 2, ""}, // back-tab stays CSI Z
+		{KeyBackspace, ModAlt, 2, "\x1b[27;3;127~"},
+		{KeyEscape, ModCtrl, 2, "\x1b[27;5;27~"},
+		{KeyEnter, 0, 2, ""},
+		{KeyEnter, ModCtrl, 1, ""},
+		{KeyUp, ModCtrl, 2, ""},
+	}
+	for _, tt := range tests {
+		if got := string(EncodeOtherKey(tt.key, tt.mod, tt.level)); got != tt.want {
+			t.Errorf("EncodeOtherKey(%d, %d, %d) = %q, want %q", tt.key, tt.mod, tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestScrollTopic(t *testing.T) {
+	tests := []struct {
+		key  ebiten.Key
+		mod  Mod
+		want string
+	}{
+		{ebiten.KeyPageUp, ModShift, "scroll_page_up"},
+		{ebiten.KeyPageDown, ModShift, "scroll_page_down"},
+		{ebiten.KeyPageUp, 0, ""},
+		{ebiten.KeyPageDown, 0, ""},
+		{ebiten.KeyPageUp, ModShift | ModCtrl, ""},
+		{ebiten.KeyHome, ModShift, ""},
+	}
+	for _, tt := range tests {
+		if got := scrollTopic(tt.key, tt.mod); got != tt.want {
+			t.Errorf("scrollTopic(%v, %d) = %q, want %q", tt.key, tt.mod, got, tt.want)
+		}
+	}
+}
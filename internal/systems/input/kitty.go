@@ -0,0 +1,181 @@
+package input
+
+import (
+	"strconv"
+	"unicode"
+
+	"gost/internal/components"
+)
+
+// -----------------------------------------------------------------------------
+// Kitty Keyboard Protocol Encoder
+// -----------------------------------------------------------------------------
+
+// KeyEvent is the kind of key event reported by the kitty protocol.
+type KeyEvent uint8
+
+const (
+	EventPress KeyEvent = 1 + iota
+	EventRepeat
+	EventRelease
+)
+
+// kittyKeys maps special keys to their kitty encodings: CSI <num> u for
+// keys with a code, CSI <num> ~ and CSI 1 <final> for the legacy forms.
+var kittyKeys = map[Key]keySeq{
+	KeyEnter:     {num: 13, final: 'u'},
+	KeyTab:       {num: 9, final: 'u'},
+	KeyBackspace: {num: 127, final: 'u'},
+	KeyEscape:    {num: 27, final: 'u'},
+	KeyUp:        {final: 'A'},
+	KeyDown:      {final: 'B'},
+	KeyRight:     {final: 'C'},
+	KeyLeft:      {final: 'D'},
+	KeyHome:      {final: 'H'},
+	KeyEnd:       {final: 'F'},
+	KeyInsert:    {num: 2, final: '~'},
+	KeyDelete:    {num: 3, final: '~'},
+	KeyPageUp:    {num: 5, final: '~'},
+	KeyPageDown:  {num: 6, final: '~'},
+	KeyF1:        {final: 'P'},
+	KeyF1 + 1:    {final: 'Q'},
+	KeyF1 + 2:    {num: 13, final: '~'}, // CSI R would read as a cursor position report
+	KeyF1 + 3:    {final: 'S'},
+	KeyF1 + 4:    {num: 15, final: '~'},
+	KeyF1 + 5:    {num: 17, final: '~'},
+	KeyF1 + 6:    {num: 18, final: '~'},
+	KeyF1 + 7:    {num: 19, final: '~'},
+	KeyF1 + 8:    {num: 20, final: '~'},
+	KeyF1 + 9:    {num: 21, final: '~'},
+	KeyF1 + 10:   {num: 23, final: '~'},
+	KeyF1 + 11:   {num: 24, final: '~'},
+}
+
+func init() {
+	for k := KeyF1 + 12; k <= KeyF24; k++ {
+		kittyKeys[k] = keySeq{num: 57376 + int(k-KeyF1-12), final: 'u'}
+	}
+	for k := KeyKP0; k <= KeyKPEqual; k++ {
+		kittyKeys[k] = keySeq{num: 57399 + int(k-KeyKP0), final: 'u'}
+	}
+	for k := KeyLeftShift; k <= KeyLeftSuper; k++ {
+		kittyKeys[k] = keySeq{num: 57441 + int(k-KeyLeftShift), final: 'u'}
+	}
+	for k := KeyRightShift; k <= KeyRightSuper; k++ {
+		kittyKeys[k] = keySeq{num: 57447 + int(k-KeyRightShift), final: 'u'}
+	}
+}
+
+// EncodeKittyKey returns the bytes for a special key under the kitty
+// keyboard flags, or nil when the event is not reported. Keys the flags
+// leave unambiguous keep their legacy encoding.
+func EncodeKittyKey(k Key, m Mod, ev KeyEvent, flags components.KeyboardFlags, km KeyModes) []byte {
+	allKeys := flags&components.KeyboardReportAllKeys != 0
+	disambiguate := flags&components.KeyboardDisambiguate != 0 || allKeys
+	if ev == EventRelease && flags&components.KeyboardReportEvents == 0 {
+		return nil
+	}
+	seq, ok := kittyKeys[k]
+	if !ok {
+		return nil
+	}
+
+	legacy := false
+	switch {
+	case k >= KeyLeftShift:
+		if !allKeys {
+			return nil
+		}
+	case k == KeyEnter || k == KeyTab || k == KeyBackspace:
+		// Unmodified, these stay legacy so a shell stays usable after a
+		// program exits without restoring the flags.
+		legacy = !allKeys && (m == 0 || !disambiguate)
+	case k == KeyEscape || k >= KeyKP0 && k <= KeyKPEqual:
+		legacy = !disambiguate
+	default:
+		legacy = !allKeys && m == 0 && ev == EventPress
+	}
+	if legacy {
+		if ev == EventRelease {
+			return nil
+		}
+		return EncodeKey(k, m, km)
+	}
+
+	b := []byte("\x1b[")
+	mods := kittyMods(m, ev, flags)
+	if seq.num != 0 {
+		b = strconv.AppendInt(b, int64(seq.num), 10)
+	} else if mods != nil {
+		b = append(b, '1')
+	}
+	if mods != nil {
+		b = append(append(b, ';'), mods...)
+	}
+	return append(b, seq.final)
+}
+
+// EncodeKittyRune returns CSI <code> u for a text key, where r is the key's
+// unshifted character and shifted the character it types with Shift.
+func EncodeKittyRune(r, shifted rune, m Mod, ev KeyEvent, flags components.KeyboardFlags) []byte {
+	text := r
+	if m&ModShift != 0 {
+		text = shifted
+	}
+	return kittyRune(r, shifted, text, m, ev, flags)
+}
+
+// EncodeKittyText returns the report-all-keys form of a character typed
+// without a key the encoder knows, e.g. through AltGr, a dead key or an
+// input method. The character serves as its own key code, lower-cased with
+// Shift for a capital, and as its own text.
+func EncodeKittyText(r rune, flags components.KeyboardFlags) []byte {
+	key, m := r, Mod(0)
+	if lower := unicode.ToLower(r); lower != r {
+		key, m = lower, ModShift
+	}
+	return kittyRune(key, r, r, m, EventPress, flags)
+}
+
+// kittyRune encodes a text key. text, the character the event typed or 0,
+// is appended when the flags report text for the event.
+func kittyRune(r, shifted, text rune, m Mod, ev KeyEvent, flags components.KeyboardFlags) []byte {
+	if ev == EventRelease && flags&components.KeyboardReportEvents == 0 {
+		return nil
+	}
+	b := []byte("\x1b[")
+	b = strconv.AppendInt(b, int64(r), 10)
+	if flags&components.KeyboardReportAlternate != 0 && m&ModShift != 0 && shifted != r {
+		b = append(b, ':')
+		b = strconv.AppendInt(b, int64(shifted), 10)
+	}
+
+	mods := kittyMods(m, ev, flags)
+	if flags&components.KeyboardReportText == 0 || flags&components.KeyboardReportAllKeys == 0 ||
+		ev == EventRelease || m&(ModCtrl|ModAlt) != 0 {
+		text = 0
+	}
+	if mods != nil || text != 0 {
+		b = append(append(b, ';'), mods...)
+	}
+	if text != 0 {
+		b = append(b, ';')
+		b = strconv.AppendInt(b, int64(text), 10)
+	}
+	return append(b, 'u')
+}
+
+// kittyMods returns the modifier field, "<1+mods>[:<event>]", or nil when
+// it can be omitted (no modifiers, and a press or events not reported).
+func kittyMods(m Mod, ev KeyEvent, flags components.KeyboardFlags) []byte {
+	withEvent := flags&components.KeyboardReportEvents != 0 && ev != EventPress
+	if m == 0 && !withEvent {
+		return nil
+	}
+	b := strconv.AppendInt(nil, int64(m)+1, 10)
+	if withEvent {
+		b = append(b, ':')
+		b = strconv.AppendInt(b, int64(ev), 10)
+	}
+	return b
+}
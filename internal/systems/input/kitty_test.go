@@ -0,0 +1,199 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"gost/internal/components"
+	"gost/internal/events"
+)
+
+func TestEncodeKittyKey(t *testing.T) {
+	const (
+		dis = components.KeyboardDisambiguate
+		evt = components.KeyboardReportEvents
+		all = components.KeyboardReportAllKeys
+	)
+	tests := []struct {
+		key   Key
+		mod   Mod
+		ev    KeyEvent
+		flags components.KeyboardFlags
+		km    KeyModes
+		want  string
+	}{
+		// Disambiguate: Escape and the keypad become CSI u; unmodified
+		// Enter, Tab and Backspace stay legacy.
+		{KeyEscape, 0, EventPress, dis, KeyModes{}, "\x1b[27u"},
+		{KeyEscape, ModAlt, EventPress, dis, KeyModes{}, "\x1b[27;3u"},
+		{KeyEnter, 0, EventPress, dis, KeyModes{}, "\r"},
+		{KeyTab, 0, EventPress, dis, KeyModes{}, "\t"},
+		{KeyBackspace, 0, EventPress, dis, KeyModes{}, "\x7f"},
+		{KeyEnter, ModShift, EventPress, dis, KeyModes{}, "\x1b[13;2u"},
+		{KeyTab, ModShift, EventPress, dis, KeyModes{}, "\x1b[9;2u"},
+		{KeyBackspace, ModCtrl, EventPress, dis, KeyModes{}, "\x1b[127;5u"},
+		{KeyKP5, 0, EventPress, dis, KeyModes{}, "\x1b[57404u"},
+		{KeyKPEnter, 0, EventPress, dis, KeyModes{}, "\x1b[57414u"},
+
+		// Functional keys keep legacy forms, modifiers as CSI 1;m.
+		{KeyUp, 0, EventPress, dis, KeyModes{}, "\x1b[A"},
+		{KeyUp, 0, EventPress, dis, KeyModes{AppCursor: true}, "\x1bOA"},
+		{KeyUp, ModCtrl, EventPress, dis, KeyModes{}, "\x1b[1;5A"},
+		{KeyDelete, ModShift, EventPress, dis, KeyModes{}, "\x1b[3;2~"},
+		{KeyF1 + 2, ModCtrl, EventPress, dis, KeyModes{}, "\x1b[13;5~"},
+		{KeyF1 + 12, 0, EventPress, all, KeyModes{}, "\x1b[57376u"},
+
+		// Event types.
+		{KeyUp, 0, EventRelease, dis, KeyModes{}, ""},
+		{KeyUp, 0, EventRelease, dis | evt, KeyModes{}, "\x1b[1;1:3A"},
+		{KeyUp, 0, EventRepeat, dis | evt, KeyModes{}, "\x1b[1;1:2A"},
+		{KeyPageUp, ModShift, EventRelease, dis | evt, KeyModes{}, "\x1b[5;2:3~"},
+		{KeyEscape, 0, EventRelease, dis | evt, KeyModes{}, "\x1b[27;1:3u"},
+		{KeyEnter, 0, EventRelease, dis | evt, KeyModes{}, ""},
+		{KeyEnter, 0, EventRelease, all | evt, KeyModes{}, "\x1b[13;1:3u"},
+
+		// Report all keys.
+		{KeyEnter, 0, EventPress, all, KeyModes{}, "\x1b[13u"},
+		{KeyUp, 0, EventPress, all, KeyModes{AppCursor: true}, "\x1b[A"},
+		{KeyLeftShift, ModShift, EventPress, all, KeyModes{}, "\x1b[57441;2u"},
+		{KeyRightCtrl, ModCtrl, EventPress, all, KeyModes{}, "\x1b[57448;5u"},
+		{KeyLeftShift, ModShift, EventPress, dis, KeyModes{}, ""},
+
+		// Report events alone leaves keys legacy.
+		{KeyEscape, 0, EventPress, evt, KeyModes{}, "\x1b"},
+		{KeyKP1, 0, EventPress, evt, KeyModes{}, "1"},
+	}
+	for _, tt := range tests {
+		got := string(EncodeKittyKey(tt.key, tt.mod, tt.ev, tt.flags, tt.km))
+		if got != tt.want {
+			t.Errorf("EncodeKittyKey(%d, %d, %d, %d, %+v) = %q, want %q",
+				tt.key, tt.mod, tt.ev, tt.flags, tt.km, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeKittyRune(t *testing.T) {
+	const (
+		dis  = components.KeyboardDisambiguate
+		evt  = components.KeyboardReportEvents
+		alt  = components.KeyboardReportAlternate
+		all  = components.KeyboardReportAllKeys
+		text = components.KeyboardReportText
+	)
+	tests := []struct {
+		r, shifted rune
+		mod        Mod
+		ev         KeyEvent
+		flags      components.KeyboardFlags
+		want       string
+	}{
+		{'i', 'I', ModCtrl, EventPress, dis, "\x1b[105;5u"}, // Ctrl+I, distinct from Tab
+		{'c', 'C', ModCtrl, EventPress, dis, "\x1b[99;5u"},
+		{'a', 'A', ModAlt, EventPress, dis, "\x1b[97;3u"},
+		{'a', 'A', ModCtrl | ModShift, EventPress, dis, "\x1b[97;6u"},
+		{'a', 'A', ModCtrl | ModShift, EventPress, dis | alt, "\x1b[97:65;6u"},
+		{'1', '!', ModAlt | ModShift, EventPress, dis | alt, "\x1b[49:33;4u"},
+		{'a', 'A', ModCtrl, EventRelease, dis, ""},
+		{'a', 'A', ModCtrl, EventRelease, dis | evt, "\x1b[97;5:3u"},
+		{'a', 'A', ModCtrl, EventRepeat, dis | evt, "\x1b[97;5:2u"},
+		{'a', 'A', 0, EventPress, all, "\x1b[97u"},
+		{'a', 'A', ModShift, EventPress, all, "\x1b[97;2u"},
+		{'a', 'A', 0, EventPress, all | text, "\x1b[97;;97u"},
+		{'a', 'A', ModShift, EventPress, all | text | alt, "\x1b[97:65;2;65u"},
+		{'a', 'A', ModCtrl, EventPress, all | text, "\x1b[97;5u"},
+		{'a', 'A', 0, EventRelease, all | text | evt, "\x1b[97;1:3u"},
+	}
+	for _, tt := range tests {
+		got := string(EncodeKittyRune(tt.r, tt.shifted, tt.mod, tt.ev, tt.flags))
+		if got != tt.want {
+			t.Errorf("EncodeKittyRune(%q, %q, %d, %d, %d) = %q, want %q",
+				tt.r, tt.shifted, tt.mod, tt.ev, tt.flags, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeKittyText(t *testing.T) {
+	const (
+		all  = components.KeyboardReportAllKeys
+		text = components.KeyboardReportText
+		alt  = components.KeyboardReportAlternate
+	)
+	tests := []struct {
+		r     rune
+		flags components.KeyboardFlags
+		want  string
+	}{
+		{'é', all, "\x1b[233u"},
+		{'é', all | text, "\x1b[233;;233u"},
+		{'É', all | text, "\x1b[233;2;201u"},
+		{'É', all | text | alt, "\x1b[233:201;2;201u"},
+		{'€', all | text, "\x1b[8364;;8364u"},
+		{'ß', all | text, "\x1b[223;;223u"},
+	}
+	for _, tt := range tests {
+		for _, ck := range chordKeys {
+			if ck.plain == tt.r || ck.shifted == tt.r {
+				t.Fatalf("%q is typed by a chord key", tt.r)
+			}
+		}
+		if got := string(EncodeKittyText(tt.r, tt.flags)); got != tt.want {
+			t.Errorf("EncodeKittyText(%q, %d) = %q, want %q", tt.r, tt.flags, got, tt.want)
+		}
+	}
+}
+
+func TestTakeChar(t *testing.T) {
+	s := &System{chars: []rune{'é', 'A', '€'}}
+	if got := s.takeChar(chordKey{'a', 'A'}); got != 'A' {
+		t.Fatalf("takeChar(a) = %q", got)
+	}
+	if got := s.takeChar(chordKey{'e', 'E'}); got != 0 {
+		t.Fatalf("takeChar(e) = %q", got)
+	}
+	// Characters no key typed are left to be sent on their own.
+	if string(s.chars) != "é€" {
+		t.Fatalf("chars left = %q", string(s.chars))
+	}
+}
+
+func TestHeldKeyRepeatsOnce(t *testing.T) {
+	var written []string
+	old := WriteToPTY
+	WriteToPTY = func(b []byte) { written = append(written, string(b)) }
+	defer func() { WriteToPTY = old }()
+
+	kb := components.NewKeyboardProtocol()
+	kb.Push(components.KeyboardReportAllKeys | components.KeyboardReportEvents | components.KeyboardReportText)
+	bus := events.NewBus()
+	defer bus.Close()
+	s := &System{bus: bus, keys: make(map[ebiten.Key]*keyState), keyboard: kb}
+
+	// A held from t0 to t0+520ms. The platform's own autorepeat delivers
+	// an 'a' now and then; only keyEvent's repeats reach the program.
+	frames := []struct {
+		at    time.Duration
+		held  bool
+		chars string
+		want  []string
+	}{
+		{0, true, "a", []string{"\x1b[97;;97u"}},
+		{100 * time.Millisecond, true, "a", nil},
+		{300 * time.Millisecond, true, "aa", nil},
+		{410 * time.Millisecond, true, "", []string{"\x1b[97;1:2;97u"}},
+		{430 * time.Millisecond, true, "a", nil},
+		{450 * time.Millisecond, true, "a", []string{"\x1b[97;1:2;97u"}},
+		{500 * time.Millisecond, true, "aé", []string{"\x1b[97;1:2;97u", "\x1b[233;;233u"}},
+		{520 * time.Millisecond, false, "", []string{"\x1b[97;1:3u"}},
+	}
+	t0 := time.Now()
+	for _, f := range frames {
+		written = nil
+		s.chars = append(s.chars[:0], []rune(f.chars)...)
+		s.sendChords(t0.Add(f.at), 0, map[ebiten.Key]bool{ebiten.KeyA: f.held})
+		if !reflect.DeepEqual(written, f.want) {
+			t.Errorf("at %v: wrote %q, want %q", f.at, written, f.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package input
+
+import (
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// -----------------------------------------------------------------------------
+// Ebiten Key Map
+// -----------------------------------------------------------------------------
+
+// specialKeys maps Ebiten keys to the encoder's special keys.
+var specialKeys = map[ebiten.Key]Key{
+	ebiten.KeyEnter:      KeyEnter,
+	ebiten.KeyTab:        KeyTab,
+	ebiten.KeyBackspace:  KeyBackspace,
+	ebiten.KeyEscape:     KeyEscape,
+	ebiten.KeyArrowUp:    KeyUp,
+	ebiten.KeyArrowDown:  KeyDown,
+	ebiten.KeyArrowRight: KeyRight,
+	ebiten.KeyArrowLeft:  KeyLeft,
+	ebiten.KeyHome:       KeyHome,
+	ebiten.KeyEnd:        KeyEnd,
+	ebiten.KeyInsert:     KeyInsert,
+	ebiten.KeyDelete:     KeyDelete,
+	ebiten.KeyPageUp:     KeyPageUp,
+	ebiten.KeyPageDown:   KeyPageDown,
+}
+
+func init() {
+	for i := 0; i < 24; i++ {
+		specialKeys[ebiten.KeyF1+ebiten.Key(i)] = KeyF1 + Key(i)
+	}
+	for i := 0; i < 10; i++ {
+		specialKeys[ebiten.KeyNumpad0+ebiten.Key(i)] = KeyKP0 + Key(i)
+	}
+	specialKeys[ebiten.KeyNumpadDecimal] = KeyKPDecimal
+	specialKeys[ebiten.KeyNumpadDivide] = KeyKPDivide
+	specialKeys[ebiten.KeyNumpadMultiply] = KeyKPMultiply
+	specialKeys[ebiten.KeyNumpadSubtract] = KeyKPSubtract
+	specialKeys[ebiten.KeyNumpadAdd] = KeyKPAdd
+	specialKeys[ebiten.KeyNumpadEnter] = KeyKPEnter
+	specialKeys[ebiten.KeyNumpadEqual] = KeyKPEqual
+
+	specialKeys[ebiten.KeyShiftLeft] = KeyLeftShift
+	specialKeys[ebiten.KeyControlLeft] = KeyLeftCtrl
+	specialKeys[ebiten.KeyAltLeft] = KeyLeftAlt
+	specialKeys[ebiten.KeyMetaLeft] = KeyLeftSuper
+	specialKeys[ebiten.KeyShiftRight] = KeyRightShift
+	specialKeys[ebiten.KeyControlRight] = KeyRightCtrl
+	specialKeys[ebiten.KeyAltRight] = KeyRightAlt
+	specialKeys[ebiten.KeyMetaRight] = KeyRightSuper
+}
+
+// keypadText reports whether a keypad key also produces a character event,
+// which carries it in numeric keypad mode.
+func keypadText(k Key) bool {
+	return k >= KeyKP0 && k <= KeyKPEqual && k != KeyKPEnter
+}
+
+// keypadHeld reports whether any keypad key that types a character is down.
+func keypadHeld() bool {
+	for k, key := range specialKeys {
+		if keypadText(key) && ebiten.IsKeyPressed(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// chordKey is the character a key produces on a US layout, unshifted and
+// shifted. Ctrl and Alt chords deliver no character events, so they are
+// polled by physical key and encoded from these, corrected for the actual
+// layout by layoutChord where the platform allows.
+type chordKey struct{ plain, shifted rune }
+
+// types reports whether r is a character the key could have typed.
+func (ck chordKey) types(r rune) bool {
+	return r == ck.plain || r == ck.shifted || unicode.ToLower(r) == ck.plain
+}
+
+var chordKeys = map[ebiten.Key]chordKey{
+	ebiten.KeySpace:        {' ', ' '},
+	ebiten.Key0:            {'0', ')'},
+	ebiten.Key1:            {'1', '!'},
+	ebiten.Key2:            {'2', '@'},
+	ebiten.Key3:            {'3', '#'},
+	ebiten.Key4:            {'4', '$'},
+	ebiten.Key5:            {'5', '%'},
+	ebiten.Key6:            {'6', '^'},
+	ebiten.Key7:            {'7', '&'},
+	ebiten.Key8:            {'8', '*'},
+	ebiten.Key9:            {'9', '('},
+	ebiten.KeyMinus:        {'-', '_'},
+	ebiten.KeyEqual:        {'=', '+'},
+	ebiten.KeyBracketLeft:  {'[', '{'},
+	ebiten.KeyBracketRight: {']', '}'},
+	ebiten.KeyBackslash:    {'\\', '|'},
+	ebiten.KeySemicolon:    {';', ':'},
+	ebiten.KeyQuote:        {'\'', '"'},
+	ebiten.KeyComma:        {',', '<'},
+	ebiten.KeyPeriod:       {'.', '>'},
+	ebiten.KeySlash:        {'/', '?'},
+	ebiten.KeyBackquote:    {'`', '~'},
+}
+
+func init() {
+	for k := ebiten.KeyA; k <= ebiten.KeyZ; k++ {
+		r := rune('a' + (k - ebiten.KeyA))
+		chordKeys[k] = chordKey{r, r - 32}
+	}
+}
+
+// layoutChord returns the characters key k types on the current keyboard
+// layout. Ebiten names a key after its unshifted character on the layout;
+// a letter's shifted form follows from it, but other keys' shifted
+// characters are only known for the US layout, so elsewhere the unshifted
+// one stands in. Characters typed through AltGr or dead keys cannot be
+// derived from the physical key at all.
+func layoutChord(k ebiten.Key, us chordKey) chordKey {
+	name := []rune(ebiten.KeyName(k))
+	if len(name) != 1 || name[0] == us.plain {
+		return us
+	}
+	r := unicode.ToLower(name[0])
+	if unicode.IsLetter(r) {
+		return chordKey{r, unicode.ToUpper(r)}
+	}
+	return chordKey{r, r}
+}
+
+// terminalShortcuts are the letters taken by the terminal's Ctrl+Shift
+// shortcuts (paste, save, reload); they never reach the shell.
+var terminalShortcuts = map[ebiten.Key]bool{
+	ebiten.KeyV: true,
+	ebiten.KeyS: true,
+	ebiten.KeyR: true,
+}
+
+// modifiers returns the held modifiers. Only the left Alt counts as meta:
+// on many layouts the right Alt is AltGr and composes characters instead.
+func modifiers() Mod {
+	var m Mod
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		m |= ModShift
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyAltLeft) {
+		m |= ModAlt
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyControl) {
+		m |= ModCtrl
+	}
+	return m
+}
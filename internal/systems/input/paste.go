@@ -0,0 +1,105 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"gost/internal/components"
+	"gost/internal/util"
+)
+
+// -----------------------------------------------------------------------------
+// Paste
+// -----------------------------------------------------------------------------
+
+const (
+	pasteStart = "\x1b[200~"
+	pasteEnd   = "\x1b[201~"
+)
+
+// bracketedPaste reports whether the application set mode 2004.
+func (s *System) bracketedPaste() bool {
+	return s.modes != nil && s.modes.Enabled(components.ModeBracketedPaste)
+}
+
+// handlePaste pastes CLIPBOARD on Ctrl+Shift+V or Shift+Insert and PRIMARY
+// on middle-click. It reports whether a paste key combination was pressed.
+// The selection is read off the frame loop: a selection owner that is slow
+// to answer would otherwise stall rendering.
+func (s *System) handlePaste() bool {
+	ctrl := ebiten.IsKeyPressed(ebiten.KeyControl)
+	shift := ebiten.IsKeyPressed(ebiten.KeyShift)
+
+	switch {
+	case ctrl && shift && inpututil.IsKeyJustPressed(ebiten.KeyV),
+		shift && inpututil.IsKeyJustPressed(ebiten.KeyInsert):
+		go func() { s.paste(util.ClipboardString()) }()
+		return true
+	case inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonMiddle):
+		go func() { s.paste(util.SelectionString(util.Primary)) }()
+		return true
+	}
+	return false
+}
+
+// paste writes text to the shell. Outside bracketed paste mode the shell
+// would run pasted lines straight away, so multi-line text and text with
+// control characters needs confirmation through the overlay first.
+func (s *System) paste(text string) {
+	if text == "" {
+		return
+	}
+	bracketed := s.bracketedPaste()
+	if bracketed || !needsPasteConfirm(text) {
+		s.writePaste(text, bracketed)
+		return
+	}
+
+	lines := strings.Count(strings.ReplaceAll(text, "\r\n", "\n"), "\n") + 1
+	s.bus.Publish("prompt_request", components.Prompt{
+		Text: fmt.Sprintf("Paste %d lines (%d bytes) into the shell?", lines, len(text)),
+		Answer: func(ok bool) {
+			if ok {
+				s.writePaste(text, s.bracketedPaste())
+			}
+		},
+	})
+}
+
+func (s *System) writePaste(text string, bracketed bool) {
+	s.publishKeyAny()
+	WriteToPTY(pasteBytes(text, bracketed))
+}
+
+// pasteBytes encodes text for the PTY: newlines become carriage returns,
+// as typed, and in bracketed mode the text is wrapped in ESC[200~ … ESC[201~
+// with any embedded markers removed so it cannot end the paste early.
+func pasteBytes(text string, bracketed bool) []byte {
+	text = strings.ReplaceAll(text, "\r\n", "\r")
+	text = strings.ReplaceAll(text, "\n", "\r")
+	if !bracketed {
+		return []byte(text)
+	}
+	for strings.Contains(text, pasteEnd) || strings.Contains(text, pasteStart) {
+		text = strings.ReplaceAll(text, pasteEnd, "")
+		text = strings.ReplaceAll(text, pasteStart, "")
+	}
+	return []byte(pasteStart + text + pasteEnd)
+}
+
+// needsPasteConfirm reports whether text spans several lines or holds
+// control characters other than tab.
+func needsPasteConfirm(text string) bool {
+	for _, r := range text {
+		if r == '\t' {
+			continue
+		}
+		if r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) {
+			return true
+		}
+	}
+	return false
+}
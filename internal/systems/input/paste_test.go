@@ -0,0 +1,124 @@
+package input
+
+import (
+	"testing"
+
+	"gost/internal/components"
+	"gost/internal/events"
+)
+
+func TestPasteBytes(t *testing.T) {
+	tests := []struct {
+		name, text string
+		bracketed  bool
+		want       string
+	}{
+		{"plain", "echo hi", false, "echo hi"},
+		{"LF to CR", "a\nb\n", false, "a\rb\r"},
+		{"CRLF to CR", "a\r\nb\r\n", false, "a\rb\r"},
+		{"lone CR kept", "a\rb", false, "a\rb"},
+		{"bracketed", "echo hi", true, "\x1b[200~echo hi\x1b[201~"},
+		{"bracketed newlines", "a\r\nb\n", true, "\x1b[200~a\rb\r\x1b[201~"},
+		{"end marker stripped", "a\x1b[201~rm -rf ~\n", true, "\x1b[200~arm -rf ~\r\x1b[201~"},
+		{"start marker stripped", "a\x1b[200~b", true, "\x1b[200~ab\x1b[201~"},
+		{"nested markers", "\x1b[20\x1b[201~1~x", true, "\x1b[200~x\x1b[201~"},
+		{"markers kept when not bracketed", "a\x1b[201~b", false, "a\x1b[201~b"},
+	}
+	for _, tt := range tests {
+		if got := string(pasteBytes(tt.text, tt.bracketed)); got != tt.want {
+			t.Errorf("%s: pasteBytes = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNeedsPasteConfirm(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"ls -la", false},
+		{"a\tb", false},
+		{"naïve 日本語", false},
+		{"one line\n", true},
+		{"two\nlines", true},
+		{"two\r\nlines", true},
+		{"carriage\rreturn", true},
+		{"escape \x1b[31m", true},
+		{"delete \x7f", true},
+		{"C1 \u009b", true},
+	}
+	for _, tt := range tests {
+		if got := needsPasteConfirm(tt.text); got != tt.want {
+			t.Errorf("needsPasteConfirm(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestPasteConfirmation(t *testing.T) {
+	var written []string
+	old := WriteToPTY
+	WriteToPTY = func(b []byte) { written = append(written, string(b)) }
+	defer func() { WriteToPTY = old }()
+
+	bus := events.NewBus()
+	prompts := bus.Subscribe("prompt_request")
+	modes := components.NewModeTable()
+	s := &System{bus: bus, modes: modes}
+
+	// A single line goes straight through.
+	s.paste("ls")
+	if len(written) != 1 || written[0] != "ls" {
+		t.Fatalf("single line wrote %q", written)
+	}
+
+	// Several lines wait for confirmation.
+	written = nil
+	s.paste("a\nb\nc")
+	var p components.Prompt
+	select {
+	case evt := <-prompts:
+		p = evt.(components.Prompt)
+	default:
+		t.Fatal("multi-line paste was not confirmed")
+	}
+	if p.Text != "Paste 3 lines (5 bytes) into the shell?" {
+		t.Errorf("prompt = %q", p.Text)
+	}
+	if len(written) != 0 {
+		t.Fatalf("wrote %q before confirmation", written)
+	}
+	p.Answer(false)
+	if len(written) != 0 {
+		t.Fatalf("declined paste wrote %q", written)
+	}
+
+	s.paste("a\r\nb")
+	p = (<-prompts).(components.Prompt)
+	if p.Text != "Paste 2 lines (4 bytes) into the shell?" {
+		t.Errorf("prompt = %q", p.Text)
+	}
+	p.Answer(true)
+	if len(written) != 1 || written[0] != "a\rb" {
+		t.Fatalf("confirmed paste wrote %q", written)
+	}
+
+	// Bracketed paste needs no confirmation.
+	written = nil
+	modes.Set(components.ModeBracketedPaste, true)
+	s.paste("a\nb")
+	select {
+	case <-prompts:
+		t.Fatal("bracketed paste asked for confirmation")
+	default:
+	}
+	if len(written) != 1 || written[0] != "\x1b[200~a\rb\x1b[201~" {
+		t.Fatalf("bracketed paste wrote %q", written)
+	}
+
+	// Nothing is written for an empty selection.
+	written = nil
+	s.paste("")
+	if len(written) != 0 {
+		t.Fatalf("empty paste wrote %q", written)
+	}
+}
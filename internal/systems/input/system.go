@@ -1,10 +1,19 @@
 package input
 
 import (
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"gost/internal/components"
 	"gost/internal/events"
+	"gost/internal/systems/config"
+	"gost/internal/util"
 )
 
 // -----------------------------------------------------------------------------
@@ -12,16 +21,29 @@ import (
 // -----------------------------------------------------------------------------
 
 type System struct {
-	bus  *events.Bus
-	keys map[ebiten.Key]*keyState
+	bus      *events.Bus
+	keys     map[ebiten.Key]*keyState
+	modes    *components.ModeTable        // terminal modes set by the parser, for key encoding
+	keyboard *components.KeyboardProtocol // kitty keyboard flags set by the parser
 
-	isSelecting bool // mouse drag active
-	lastX, lastY int // last cursor position for selection
+	isSelecting  bool // mouse drag active
+	lastX, lastY int  // last cursor position for selection
+
+	linkMu      sync.Mutex
+	hoverURI    string   // hyperlink under the pointer, reported by the renderer
+	linkOpener  string   // command that opens a Ctrl+clicked hyperlink
+	linkSchemes []string // URI schemes the opener may be given
+
+	promptActive atomic.Bool // an overlay prompt owns the keyboard
+	keysHeld     bool        // keys from a prompt answer still down; wait for release
+
+	chars []rune // scratch buffer for this frame's typed characters
 }
 
 type keyState struct {
 	pressed bool
 	next    time.Time
+	text    rune // the character the last press typed, for its repeats
 }
 
 // --- Key timing constants ---
@@ -45,12 +67,128 @@ var WriteToPTY = func(b []byte) {}
 // -----------------------------------------------------------------------------
 
 func NewSystem(bus *events.Bus) *System {
-	return &System{
-		bus:  bus,
-		keys: make(map[ebiten.Key]*keyState),
+	s := &System{
+		bus:        bus,
+		keys:       make(map[ebiten.Key]*keyState),
+		linkOpener: util.DefaultOpener,
+	}
+	s.subscribeLinks()
+	s.subscribePrompts()
+	return s
+}
+
+// AttachModes gives the system the parser's mode table, consulted for
+// application cursor keys (DECCKM), the application keypad (DECKPAM) and
+// bracketed paste.
+func (s *System) AttachModes(modes *components.ModeTable) {
+	s.modes = modes
+}
+
+// AttachKeyboard gives the system the parser's kitty keyboard flag stacks.
+func (s *System) AttachKeyboard(kb *components.KeyboardProtocol) {
+	s.keyboard = kb
+}
+
+// keyboardFlags returns the kitty keyboard flags in effect, 0 for legacy
+// encoding.
+func (s *System) keyboardFlags() components.KeyboardFlags {
+	if s.keyboard == nil {
+		return 0
+	}
+	return s.keyboard.Flags()
+}
+
+// modifyOtherKeys returns xterm's modifyOtherKeys level, 0 when off.
+func (s *System) modifyOtherKeys() int {
+	if s.keyboard == nil {
+		return 0
+	}
+	return s.keyboard.ModifyOtherKeys()
+}
+
+// disambiguates reports whether the kitty flags replace the legacy encoding
+// of ambiguous keys: Ctrl and Alt chords, Escape and the keypad.
+func disambiguates(flags components.KeyboardFlags) bool {
+	return flags&(components.KeyboardDisambiguate|components.KeyboardReportAllKeys) != 0
+}
+
+// keyModes returns the terminal modes that affect key encoding.
+func (s *System) keyModes() KeyModes {
+	if s.modes == nil {
+		return KeyModes{}
+	}
+	return KeyModes{
+		AppCursor: s.modes.Enabled(components.ModeCursorKeys),
+		AppKeypad: s.modes.Enabled(components.ModeKeypad),
 	}
 }
 
+// subscribePrompts holds keyboard input back while an overlay prompt is up.
+func (s *System) subscribePrompts() {
+	if s.bus == nil {
+		return
+	}
+	sub := s.bus.Subscribe("prompt_active")
+	go func() {
+		for evt := range sub {
+			if active, ok := evt.(bool); ok {
+				s.promptActive.Store(active)
+			}
+		}
+	}()
+}
+
+// keyboardBlocked reports whether keys must not reach the shell: while a
+// prompt is shown, and until the key that answered it is released.
+func (s *System) keyboardBlocked() bool {
+	if s.promptActive.Load() {
+		s.keysHeld = true
+		return true
+	}
+	if s.keysHeld {
+		s.keysHeld = len(inpututil.AppendPressedKeys(nil)) > 0
+	}
+	return s.keysHeld
+}
+
+// subscribeLinks tracks the hovered hyperlink and the configured opener.
+func (s *System) subscribeLinks() {
+	if s.bus == nil {
+		return
+	}
+	hoverSub := s.bus.Subscribe("link_hover")
+	loadedSub := s.bus.Subscribe("config_loaded")
+	changedSub := s.bus.Subscribe("config_changed")
+
+	go func() {
+		for evt := range hoverSub {
+			if uri, ok := evt.(string); ok {
+				s.linkMu.Lock()
+				s.hoverURI = uri
+				s.linkMu.Unlock()
+			}
+		}
+	}()
+	applyConfig := func(evt events.Event) {
+		if cfg, ok := evt.(*config.RootConfig); ok {
+			s.linkMu.Lock()
+			s.linkOpener = cfg.Links.Opener
+			s.linkSchemes = cfg.Links.Schemes
+			s.linkMu.Unlock()
+		}
+	}
+	go func() {
+		for evt := range loadedSub {
+			applyConfig(evt)
+		}
+	}()
+	go func() {
+		for evt := range changedSub {
+			applyConfig(evt)
+		}
+	}()
+}
+
 // -----------------------------------------------------------------------------
 // ECS Loop
 // -----------------------------------------------------------------------------
@@ -58,11 +196,13 @@ func NewSystem(bus *events.Bus) *System {
 func (s *System) UpdateECS() {
 	now := time.Now()
 
-	s.handlePrintable(now)
-	s.handleSpecial(now)
-	s.handleScrollback(now)
+	if !s.keyboardBlocked() && !s.handlePaste() {
+		s.handlePrintable(now)
+		s.handleChords(now)
+		s.handleSpecial(now)
+		s.handleGlobalHotkeys(now)
+	}
 	s.handleMouseScroll(now)
-	s.handleGlobalHotkeys(now)
 	s.handleSelection()
 }
 
@@ -70,44 +210,182 @@ func (s *System) UpdateECS() {
 // Keyboard Input
 // -----------------------------------------------------------------------------
 
+// handlePrintable forwards typed text from Ebiten's character events, which
+// follow the keyboard layout and deliver one rune per keystroke or repeat.
 func (s *System) handlePrintable(now time.Time) {
-	for k := ebiten.KeyA; k <= ebiten.KeyZ; k++ {
-		if ebiten.IsKeyPressed(k) {
-			s.publishKeyAny()
-			alt := ebiten.IsKeyPressed(ebiten.KeyAlt)
-			b := byte('a' + (k - ebiten.KeyA))
-			if ebiten.IsKeyPressed(ebiten.KeyShift) {
-				b -= 32 // uppercase
+	s.chars = ebiten.AppendInputChars(s.chars[:0])
+	// Ctrl and Alt chords are encoded by handleChords.
+	if len(s.chars) == 0 || modifiers()&(ModCtrl|ModAlt) != 0 {
+		s.chars = s.chars[:0]
+		return
+	}
+	// In application keypad mode handleSpecial encodes the keypad, so drop
+	// the characters it typed.
+	flags := s.keyboardFlags()
+	dropKeypad := (s.keyModes().AppKeypad || disambiguates(flags)) && keypadHeld()
+	kept := s.chars[:0]
+	for _, r := range s.chars {
+		if dropKeypad && strings.ContainsRune("0123456789./*-+=", r) {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	s.chars = kept
+	// Under the kitty "report all keys" flag handleChords sends the
+	// characters, along with the keys that typed them.
+	if len(s.chars) == 0 || flags&components.KeyboardReportAllKeys != 0 {
+		return
+	}
+	buf := make([]byte, 0, len(s.chars)*utf8.UTFMax)
+	for _, r := range s.chars {
+		buf = utf8.AppendRune(buf, r)
+	}
+	s.publishKeyAny()
+	WriteToPTY(buf)
+}
+
+// handleChords sends Ctrl and Alt combinations with character keys, such as
+// Ctrl+C or Alt+B, and all character keys when the kitty flags report every
+// key. The kitty protocol takes precedence over modifyOtherKeys. Ctrl+Shift
+// chords on terminalShortcuts are left to the terminal.
+func (s *System) handleChords(now time.Time) {
+	m := modifiers()
+	allKeys := s.keyboardFlags()&components.KeyboardReportAllKeys != 0
+	shortcut := m&(ModCtrl|ModShift) == ModCtrl|ModShift
+	held := make(map[ebiten.Key]bool)
+	for k := range chordKeys {
+		if (m&(ModCtrl|ModAlt) != 0 || allKeys) && !(shortcut && terminalShortcuts[k]) && ebiten.IsKeyPressed(k) {
+			held[k] = true
+		}
+	}
+	s.sendChords(now, m, held)
+}
+
+// sendChords encodes this frame's events for the chord keys, given those
+// held down.
+//
+// When every key is reported, this frame's typed characters remain the text
+// source: each key event carries the character it typed, and characters no
+// known key accounts for (AltGr, dead keys, input methods) are sent on their
+// own. Repeats come from keyEvent alone; the platform's autorepeat
+// characters for a held key are dropped rather than sent as extra presses.
+func (s *System) sendChords(now time.Time, m Mod, held map[ebiten.Key]bool) {
+	flags := s.keyboardFlags()
+	level := s.modifyOtherKeys()
+	allKeys := flags&components.KeyboardReportAllKeys != 0
+	var typing []chordKey // what the held keys type, for dropping autorepeat
+	for k, ck := range chordKeys {
+		ev := s.keyEvent(now, k, held[k])
+		if ev == 0 && !held[k] {
+			continue
+		}
+		ck = layoutChord(k, ck)
+		if held[k] {
+			typing = append(typing, ck)
+		}
+		if ev == 0 || m == ModAlt|ModShift && k == ebiten.KeyC {
+			continue // nothing due, or the exit hotkey
+		}
+
+		var seq []byte
+		switch {
+		case disambiguates(flags):
+			var text rune
+			if allKeys && ev != EventRelease {
+				text = s.takeChar(ck)
+				// A repeat the platform typed nothing for repeats the press's text.
+				if ks := s.keys[k]; ev == EventPress {
+					ks.text = text
+				} else if text == 0 {
+					text = ks.text
+				}
 			}
-			WriteToPTY(buildSeq(alt, b))
+			seq = kittyRune(ck.plain, ck.shifted, text, m, ev, flags)
+		case ev != EventRelease:
+			r := ck.plain
+			if m&ModShift != 0 {
+				r = ck.shifted
+			}
+			if seq = EncodeOtherKeys(r, m, level); seq == nil {
+				seq = EncodeRune(r, m)
+			}
+		}
+		if seq == nil {
+			continue
 		}
+		s.publishKeyAny()
+		WriteToPTY(seq)
 	}
 
-	for k := ebiten.Key0; k <= ebiten.Key9; k++ {
-		if ebiten.IsKeyPressed(k) {
+	if allKeys {
+	chars:
+		for _, r := range s.chars {
+			for _, ck := range typing {
+				if ck.types(r) {
+					continue chars
+				}
+			}
 			s.publishKeyAny()
-			WriteToPTY(buildSeq(ebiten.IsKeyPressed(ebiten.KeyAlt), byte('0'+(k-ebiten.Key0))))
+			WriteToPTY(EncodeKittyText(r, flags))
+		}
+		s.chars = s.chars[:0]
+	}
+}
+
+// takeChar removes from this frame's typed characters the first one key ck
+// could have typed, and returns it, or 0 if there is none.
+func (s *System) takeChar(ck chordKey) rune {
+	for i, r := range s.chars {
+		if ck.types(r) {
+			s.chars = append(s.chars[:i], s.chars[i+1:]...)
+			return r
 		}
 	}
+	return 0
 }
 
+// handleSpecial sends control, navigation and function keys, except the
+// chords bound to scrollback and paste.
 func (s *System) handleSpecial(now time.Time) {
-	keySeqs := map[ebiten.Key][]byte{
-		ebiten.KeyEnter:      {'\r'},
-		ebiten.KeyBackspace:  {0x7f},
-		ebiten.KeyTab:        {'\t'},
-		ebiten.KeyEscape:     {0x1b},
-		ebiten.KeyArrowUp:    []byte{0x1b, '[', 'A'},
-		ebiten.KeyArrowDown:  []byte{0x1b, '[', 'B'},
-		ebiten.KeyArrowRight: []byte{0x1b, '[', 'C'},
-		ebiten.KeyArrowLeft:  []byte{0x1b, '[', 'D'},
-	}
-
-	for k, seq := range keySeqs {
-		if ebiten.IsKeyPressed(k) {
-			s.publishKeyAny()
-			WriteToPTY(seq)
+	m := modifiers()
+	km := s.keyModes()
+	flags := s.keyboardFlags()
+	level := s.modifyOtherKeys()
+	for k, key := range specialKeys {
+		ev := s.keyEvent(now, k, ebiten.IsKeyPressed(k))
+		if ev == 0 {
+			continue
+		}
+		if keypadText(key) && !km.AppKeypad && !disambiguates(flags) {
+			continue // typed through character events
+		}
+		if topic := scrollTopic(k, m); topic != "" {
+			if ev != EventRelease {
+				s.bus.Publish(topic, nil)
+			}
+			continue
 		}
+		if k == ebiten.KeyInsert && m == ModShift {
+			continue // paste
+		}
+		if k == ebiten.KeyEnd && m == ModCtrl {
+			continue // back to the live screen, handled by the viewport
+		}
+
+		var seq []byte
+		switch {
+		case flags != 0:
+			seq = EncodeKittyKey(key, m, ev, flags, km)
+		case ev != EventRelease:
+			if seq = EncodeOtherKey(key, m, level); seq == nil {
+				seq = EncodeKey(key, m, km)
+			}
+		}
+		if seq == nil {
+			continue
+		}
+		s.publishKeyAny()
+		WriteToPTY(seq)
 	}
 }
 
@@ -115,31 +393,32 @@ func (s *System) handleSpecial(now time.Time) {
 // Scrollback and Hotkeys
 // -----------------------------------------------------------------------------
 
-func (s *System) handleScrollback(now time.Time) {
-	pageUp := ebiten.IsKeyPressed(ebiten.KeyPageUp)
-	pageDown := ebiten.IsKeyPressed(ebiten.KeyPageDown)
-	shift := ebiten.IsKeyPressed(ebiten.KeyShift)
-
-	if shift {
-		handleBusKey(s, now, ebiten.KeyPageUp, pageUp, "scroll_page_up")
-		handleBusKey(s, now, ebiten.KeyPageDown, pageDown, "scroll_page_down")
-	} else {
-		handleBusKey(s, now, ebiten.KeyPageUp, pageUp, "scroll_up")
-		handleBusKey(s, now, ebiten.KeyPageDown, pageDown, "scroll_down")
+// scrollTopic returns the scrollback event bound to a key chord, or "".
+// As in xterm only Shift+PageUp/PageDown page through history; without
+// Shift the keys go to the program, so less and vim can page.
+func scrollTopic(k ebiten.Key, m Mod) string {
+	switch {
+	case m == ModShift && k == ebiten.KeyPageUp:
+		return "scroll_page_up"
+	case m == ModShift && k == ebiten.KeyPageDown:
+		return "scroll_page_down"
 	}
+	return ""
 }
 
+// handleGlobalHotkeys handles the terminal's own chords. Save and reload
+// take Shift so that Ctrl+S and Ctrl+R reach the shell.
 func (s *System) handleGlobalHotkeys(now time.Time) {
 	ctrl := ebiten.IsKeyPressed(ebiten.KeyControl)
 	alt := ebiten.IsKeyPressed(ebiten.KeyAlt)
 	shift := ebiten.IsKeyPressed(ebiten.KeyShift)
 
-	if ctrl && ebiten.IsKeyPressed(ebiten.KeyS) && now.Sub(lastSave) > saveCooldown {
+	if ctrl && shift && ebiten.IsKeyPressed(ebiten.KeyS) && now.Sub(lastSave) > saveCooldown {
 		lastSave = now
 		s.bus.Publish("config_save_requested", nil)
 	}
 
-	if ctrl && ebiten.IsKeyPressed(ebiten.KeyR) {
+	if ctrl && shift && inpututil.IsKeyJustPressed(ebiten.KeyR) {
 		s.bus.Publish("config_reload_requested", nil)
 	}
 
@@ -171,6 +450,10 @@ func (s *System) handleSelection() {
 	x, y := ebiten.CursorPosition()
 	leftPressed := ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
 
+	if !s.isSelecting && s.handleLinkClick() {
+		return
+	}
+
 	if leftPressed && !s.isSelecting {
 		s.isSelecting = true
 		s.lastX, s.lastY = x, y
@@ -192,38 +475,58 @@ func (s *System) handleSelection() {
 	}
 }
 
+// handleLinkClick opens the hovered hyperlink on Ctrl+click. It reports
+// whether the pointer is over a link with Ctrl held, in which case the
+// click must not start a selection.
+func (s *System) handleLinkClick() bool {
+	s.linkMu.Lock()
+	uri, opener, schemes := s.hoverURI, s.linkOpener, s.linkSchemes
+	s.linkMu.Unlock()
+
+	if uri == "" || !ebiten.IsKeyPressed(ebiten.KeyControl) {
+		return false
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		if err := util.OpenURI(opener, uri, schemes); err != nil {
+			log.Println("[Input] open link failed:", err)
+		} else {
+			log.Println("[Input] opened link:", uri)
+		}
+	}
+	return true
+}
+
 // -----------------------------------------------------------------------------
 // Helpers
 // -----------------------------------------------------------------------------
 
-func handleBusKey(s *System, now time.Time, key ebiten.Key, pressed bool, topic string) {
+// keyEvent tracks a polled key and returns its event this frame: a press
+// when it goes down, a repeat every repeatRate after repeatDelay while held,
+// a release when it comes up, or 0.
+func (s *System) keyEvent(now time.Time, key ebiten.Key, pressed bool) KeyEvent {
 	ks, ok := s.keys[key]
 	if !ok {
 		ks = &keyState{}
 		s.keys[key] = ks
 	}
 
-	if pressed {
-		if !ks.pressed {
-			ks.pressed = true
-			ks.next = now.Add(repeatDelay)
-			s.bus.Publish(topic, nil)
-			return
+	if !pressed {
+		if ks.pressed {
+			ks.pressed = false
+			return EventRelease
 		}
-		if now.After(ks.next) {
-			ks.next = now.Add(repeatRate)
-			s.bus.Publish(topic, nil)
-		}
-	} else {
-		ks.pressed = false
+		return 0
 	}
-}
-
-func buildSeq(alt bool, b byte) []byte {
-	if alt {
-		return append([]byte{0x1b}, b)
+	if !ks.pressed {
+		ks.pressed = true
+		ks.next = now.Add(repeatDelay)
+		return EventPress
+	}
+	if now.After(ks.next) {
+		ks.next = now.Add(repeatRate)
+		return EventRepeat
 	}
-	return []byte{b}
+	return 0
 }
 
 func (s *System) publishKeyAny() {
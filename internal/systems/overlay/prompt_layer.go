@@ -0,0 +1,125 @@
+package overlay
+
+import (
+	"image/color"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+
+	"gost/internal/components"
+	"gost/internal/events"
+)
+
+// -----------------------------------------------------------------------------
+// PromptLayer — modal yes/no questions
+// -----------------------------------------------------------------------------
+
+// maxPendingPrompts bounds the queue so a program cannot flood the user
+// with questions; extra prompts are refused.
+const maxPendingPrompts = 4
+
+// PromptLayer shows queued components.Prompt questions one at a time and
+// answers them from Y/Enter (yes) or N/Escape (no). While a prompt is shown
+// it publishes "prompt_active" true so keyboard input is held back from
+// the shell.
+type PromptLayer struct {
+	bus     *events.Bus
+	mu      sync.Mutex
+	pending []components.Prompt
+	shown   bool // "prompt_active" true has been published
+	font    font.Face
+}
+
+// NewPromptLayer creates a prompt overlay fed by "prompt_request".
+func NewPromptLayer(bus *events.Bus) *PromptLayer {
+	p := &PromptLayer{bus: bus, font: basicfont.Face7x13}
+	p.subscribePrompts()
+	return p
+}
+
+func (p *PromptLayer) subscribePrompts() {
+	if p.bus == nil {
+		return
+	}
+	sub := p.bus.Subscribe("prompt_request")
+	go func() {
+		for evt := range sub {
+			if req, ok := evt.(components.Prompt); ok {
+				p.enqueue(req)
+			}
+		}
+	}()
+}
+
+func (p *PromptLayer) enqueue(req components.Prompt) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.pending) >= maxPendingPrompts {
+		if req.Answer != nil {
+			req.Answer(false)
+		}
+		return
+	}
+	p.pending = append(p.pending, req)
+}
+
+// -----------------------------------------------------------------------------
+// ECS Update
+// -----------------------------------------------------------------------------
+
+// UpdateECS answers the front prompt when the user presses a key.
+func (p *PromptLayer) UpdateECS() {
+	p.mu.Lock()
+	var done *components.Prompt
+	var answer bool
+	if len(p.pending) > 0 {
+		switch {
+		case inpututil.IsKeyJustPressed(ebiten.KeyY), inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+			done, answer = &p.pending[0], true
+		case inpututil.IsKeyJustPressed(ebiten.KeyN), inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+			done, answer = &p.pending[0], false
+		}
+	}
+	var req components.Prompt
+	if done != nil {
+		req = *done
+		p.pending = p.pending[1:]
+	}
+	active := len(p.pending) > 0
+	changed := active != p.shown
+	p.shown = active
+	p.mu.Unlock()
+
+	if done != nil && req.Answer != nil {
+		req.Answer(answer)
+	}
+	if changed && p.bus != nil {
+		p.bus.Publish("prompt_active", active)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Draw
+// -----------------------------------------------------------------------------
+
+// Draw renders the front prompt in a box along the bottom of the screen.
+func (p *PromptLayer) Draw(screen *ebiten.Image) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.pending) == 0 {
+		return
+	}
+
+	msg := p.pending[0].Text + "  [y/n]"
+	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+	boxH := 24
+	y0 := h - boxH - 8
+	ebitenutil.DrawRect(screen, 8, float64(y0), float64(w-16), float64(boxH), color.RGBA{30, 30, 60, 230})
+	ebitenutil.DrawRect(screen, 8, float64(y0), float64(w-16), 1, color.RGBA{120, 120, 255, 255})
+	text.Draw(screen, msg, p.font, 16, y0+16, color.White)
+}
@@ -45,13 +45,16 @@ type System struct {
     bus    *events.Bus
 }
 
-// NewSystem creates an empty overlay compositor.
-func NewSystem() *System {
-    return &System{
+// NewSystem creates an overlay compositor with the prompt layer on top.
+func NewSystem(bus *events.Bus) *System {
+    o := &System{
         layers: make([]Drawable, 0, 8),
         msgs:   make([]*Message, 0, 8),
         font:   basicfont.Face7x13,
+        bus:    bus,
     }
+    o.AddLayer(NewPromptLayer(bus))
+    return o
 }
 
 // -----------------------------------------------------------------------------
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"gost/internal/components"
+	"gost/internal/events"
+)
+
+func TestAltScreen1049(t *testing.T) {
+	buf := components.NewTermBuffer(5, 3)
+	sb := components.NewScrollback(100)
+	buf.AttachScrollback(sb)
+	s := NewSystem(events.NewBus(), buf)
+	s.feed([]byte("one\r\ntwo"))
+	primary := screenRows(buf)
+
+	s.feed([]byte("\x1b[?1049h"))
+	if !buf.AltScreenActive() {
+		t.Fatal("1049h did not switch to the alternate screen")
+	}
+	if got := screenRows(buf); !reflect.DeepEqual(got, []string{"     ", "     ", "     "}) {
+		t.Fatalf("alternate screen on entry = %q", got)
+	}
+
+	// Scrolling the alternate screen never reaches scrollback.
+	s.feed([]byte("\x1b[Ha\r\nb\r\nc\r\nd\r\ne\x1b[S"))
+	if n := sb.Count(); n != 0 {
+		t.Fatalf("alternate screen pushed %d lines to scrollback", n)
+	}
+
+	s.feed([]byte("\x1b[?1049l"))
+	if buf.AltScreenActive() {
+		t.Fatal("1049l did not leave the alternate screen")
+	}
+	if got := screenRows(buf); !reflect.DeepEqual(got, primary) {
+		t.Fatalf("primary screen = %q, want %q", got, primary)
+	}
+	if s.cx != 3 || s.cy != 1 {
+		t.Fatalf("cursor restored to (%d,%d), want (3,1)", s.cx, s.cy)
+	}
+
+	// The alternate screen is cleared again on the next entry.
+	s.feed([]byte("\x1b[?1049h"))
+	if got := rowText(buf, 0, 5); got != "     " {
+		t.Fatalf("stale alternate screen on re-entry: %q", got)
+	}
+	s.feed([]byte("\x1b[?1049l"))
+}
+
+func TestAltScreenModes(t *testing.T) {
+	tests := []struct {
+		mode      string
+		keepsText bool // alternate text still there on the next entry
+	}{
+		{"47", true},
+		{"1047", false},
+	}
+	for _, tt := range tests {
+		buf := components.NewTermBuffer(5, 2)
+		s := NewSystem(events.NewBus(), buf)
+		s.feed([]byte("main"))
+
+		s.feed([]byte("\x1b[?" + tt.mode + "h\x1b[Halt"))
+		s.feed([]byte("\x1b[?" + tt.mode + "l"))
+		if got := rowText(buf, 0, 4); got != "main" {
+			t.Errorf("%s: primary row = %q", tt.mode, got)
+		}
+
+		s.feed([]byte("\x1b[?" + tt.mode + "h"))
+		want := "    "
+		if tt.keepsText {
+			want = "alt "
+		}
+		if got := rowText(buf, 0, 4); got != want {
+			t.Errorf("%s: alternate row on re-entry = %q, want %q", tt.mode, got, want)
+		}
+	}
+}
+
+func TestSaveCursorMode1048(t *testing.T) {
+	buf := components.NewTermBuffer(5, 3)
+	s := NewSystem(events.NewBus(), buf)
+	s.feed([]byte("\x1b[2;3H\x1b[?1048h\x1b[H\x1b[?1048l"))
+	if s.cx != 2 || s.cy != 1 {
+		t.Fatalf("cursor restored to (%d,%d), want (2,1)", s.cx, s.cy)
+	}
+	if buf.AltScreenActive() {
+		t.Fatal("1048 switched screens")
+	}
+}
@@ -0,0 +1,79 @@
+package parser
+
+// -----------------------------------------------------------------------------
+// Character Sets (G0–G3, SI/SO, SS2/SS3)
+// -----------------------------------------------------------------------------
+
+type charset int
+
+const (
+	charsetASCII      charset = iota // ESC ( B
+	charsetUK                        // ESC ( A — '#' becomes '£'
+	charsetDECSpecial                // ESC ( 0 — line drawing
+)
+
+// charsetFor maps the final byte of a designation sequence to a charset.
+func charsetFor(final rune) (charset, bool) {
+	switch final {
+	case 'B':
+		return charsetASCII, true
+	case 'A':
+		return charsetUK, true
+	case '0':
+		return charsetDECSpecial, true
+	}
+	return charsetASCII, false
+}
+
+// decSpecialGraphics is the VT100 line-drawing set for 0x60–0x7E.
+var decSpecialGraphics = [...]rune{
+	'◆', '▒', '␉', '␌', '␍', '␊', '°', '±', // ` a b c d e f g
+	'␤', '␋', '┘', '┐', '┌', '└', '┼', '⎺', // h i j k l m n o
+	'⎻', '─', '⎼', '⎽', '├', '┤', '┴', '┬', // p q r s t u v w
+	'│', '≤', '≥', 'π', '≠', '£', '·', // x y z { | } ~
+}
+
+// translate maps an ASCII rune through the charset.
+func (c charset) translate(r rune) rune {
+	switch c {
+	case charsetUK:
+		if r == '#' {
+			return '£'
+		}
+	case charsetDECSpecial:
+		if r == 0x5F {
+			return ' ' // blank
+		}
+		if r >= 0x60 && r <= 0x7E {
+			return decSpecialGraphics[r-0x60]
+		}
+	}
+	return r
+}
+
+// designate stores a charset into one of G0–G3 (ESC ( ) * + final).
+func (s *System) designate(slot int, final rune) {
+	if cs, ok := charsetFor(final); ok && slot >= 0 && slot < len(s.charsets) {
+		s.charsets[slot] = cs
+	}
+}
+
+// translateCharset applies the active (or single-shifted) charset to a
+// printable ASCII rune.
+func (s *System) translateCharset(r rune) rune {
+	slot := s.gl
+	if s.singleShift != 0 {
+		slot = s.singleShift
+		s.singleShift = 0
+	}
+	if r < 0x20 || r > 0x7E {
+		return r
+	}
+	return s.charsets[slot].translate(r)
+}
+
+func (s *System) resetCharsets() {
+	s.charsets = [4]charset{}
+	s.gl = 0
+	s.singleShift = 0
+}
@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"testing"
+
+	"gost/internal/components"
+	"gost/internal/events"
+)
+
+// rowText returns the first n cells of row y as a string.
+func rowText(buf *components.TermBuffer, y, n int) string {
+	var out []rune
+	for x := 0; x < n; x++ {
+		out = append(out, buf.GetRune(x, y).Rune)
+	}
+	return string(out)
+}
+
+func TestCharsets(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"DEC special", "\x1b(0lqk_x\x1b(B", "┌─┐ │"},
+		{"UK", "\x1b(A#\x1b(B#", "£#"},
+		{"SO and SI", "\x1b)0q\x0eq\x0fq", "q─q"},
+		{"SS2", "\x1b*0q\x1bNqq", "q─q"},
+		{"SS3", "\x1b+Aq\x1bO##", "q£#"},
+		{"LS2", "\x1b*0\x1bnqq\x0fq", "──q"},
+		{"single shift then SO", "\x1b)0\x1b+A\x0e\x1bO#q\x0fq", "£─q"},
+	}
+	for _, tt := range tests {
+		buf := components.NewTermBuffer(10, 1)
+		s := NewSystem(events.NewBus(), buf)
+		s.feed([]byte(tt.input))
+		if got := rowText(buf, 0, len([]rune(tt.want))); got != tt.want {
+			t.Errorf("%s: row = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDECSpecialGraphicsRange(t *testing.T) {
+	if got := charsetDECSpecial.translate('_'); got != ' ' {
+		t.Errorf("0x5F = %q, want blank", got)
+	}
+	if got := charsetDECSpecial.translate('^'); got != '^' {
+		t.Errorf("0x5E = %q, want unchanged", got)
+	}
+	if got := charsetDECSpecial.translate('~'); got != '·' {
+		t.Errorf("0x7E = %q, want '·'", got)
+	}
+	if got := charsetDECSpecial.translate('A'); got != 'A' {
+		t.Errorf("'A' = %q, want unchanged", got)
+	}
+}
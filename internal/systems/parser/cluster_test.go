@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"testing"
+
+	"gost/internal/components"
+	"gost/internal/events"
+)
+
+func TestCombiningMarkAfterWideChar(t *testing.T) {
+	buf := components.NewTermBuffer(10, 2)
+	s := NewSystem(events.NewBus(), buf)
+	s.feed([]byte("中\u0301x"))
+
+	if g := buf.GetRune(0, 0); g.Text() != "中\u0301" || !g.IsWide() {
+		t.Fatalf("cell 0 = %q", g.Text())
+	}
+	if !buf.GetRune(1, 0).IsWideCont() {
+		t.Fatal("cell 1 is not the wide continuation")
+	}
+	if g := buf.GetRune(2, 0); g.Rune != 'x' {
+		t.Fatalf("cell 2 = %q", g.Rune)
+	}
+}
+
+func TestZWJSequences(t *testing.T) {
+	tests := []struct {
+		name, text string
+		cx         int
+	}{
+		{"woman technologist", "\U0001F469\u200d\U0001F4BB", 2},
+		{"family", "\U0001F468\u200d\U0001F469\u200d\U0001F467", 2},
+		{"skin tone", "\U0001F44D\U0001F3FD", 2},
+		{"flag", "\U0001F1EF\U0001F1F5", 2},
+		{"heart with VS16", "\u2764\ufe0f", 2},
+	}
+	for _, tt := range tests {
+		buf := components.NewTermBuffer(10, 2)
+		s := NewSystem(events.NewBus(), buf)
+		s.feed([]byte(tt.text + "x"))
+
+		g := buf.GetRune(0, 0)
+		if g.Text() != tt.text || !g.IsWide() {
+			t.Errorf("%s: cell 0 = %q, wide = %v", tt.name, g.Text(), g.IsWide())
+		}
+		if got := buf.GetRune(tt.cx, 0).Rune; got != 'x' {
+			t.Errorf("%s: next character at column %d is %q", tt.name, tt.cx, got)
+		}
+	}
+}
+
+func TestZWJAcrossFeeds(t *testing.T) {
+	buf := components.NewTermBuffer(10, 2)
+	s := NewSystem(events.NewBus(), buf)
+	s.feed([]byte("\U0001F469\u200d"))
+	s.feed([]byte("\U0001F4BB"))
+
+	if got := buf.GetRune(0, 0).Text(); got != "\U0001F469\u200d\U0001F4BB" {
+		t.Fatalf("cell 0 = %q", got)
+	}
+}
+
+func TestClusterInterruptedByEscape(t *testing.T) {
+	buf := components.NewTermBuffer(10, 2)
+	s := NewSystem(events.NewBus(), buf)
+	// A control sequence ends the cluster; the ZWJ stays on the first cell.
+	s.feed([]byte("\U0001F469\u200d\x1b[1m\U0001F4BB"))
+
+	if got := buf.GetRune(0, 0).Text(); got != "\U0001F469\u200d" {
+		t.Fatalf("cell 0 = %q", got)
+	}
+	if got := buf.GetRune(2, 0).Text(); got != "\U0001F4BB" {
+		t.Fatalf("cell 2 = %q", got)
+	}
+}
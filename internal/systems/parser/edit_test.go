@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"gost/internal/components"
+	"gost/internal/events"
+)
+
+// screenRows returns every row of buf as a string.
+func screenRows(buf *components.TermBuffer) []string {
+	rows := make([]string, buf.Height)
+	for y := range rows {
+		rows[y] = rowText(buf, y, buf.Width)
+	}
+	return rows
+}
+
+func TestEditingSequences(t *testing.T) {
+	const fill = "AAAAAA\r\nBBBBBB\r\nCCCCCC\r\nDDDDDD\r\nEEEEEE"
+	tests := []struct {
+		name, input string
+		want        []string
+	}{
+		{"IL", "\x1b[2;1H\x1b[L", []string{"AAAAAA", "      ", "BBBBBB", "CCCCCC", "DDDDDD"}},
+		{"IL 2", "\x1b[4;1H\x1b[2L", []string{"AAAAAA", "BBBBBB", "CCCCCC", "      ", "      "}},
+		{"DL", "\x1b[2;1H\x1b[M", []string{"AAAAAA", "CCCCCC", "DDDDDD", "EEEEEE", "      "}},
+		{"IL in region", "\x1b[2;4r\x1b[2;1H\x1b[L", []string{"AAAAAA", "      ", "BBBBBB", "CCCCCC", "EEEEEE"}},
+		{"DL in region", "\x1b[2;4r\x1b[2;1H\x1b[M", []string{"AAAAAA", "CCCCCC", "DDDDDD", "      ", "EEEEEE"}},
+		{"IL outside region", "\x1b[2;4r\x1b[5;1H\x1b[L", []string{"AAAAAA", "BBBBBB", "CCCCCC", "DDDDDD", "EEEEEE"}},
+		{"DL outside region", "\x1b[2;4r\x1b[1;1H\x1b[M", []string{"AAAAAA", "BBBBBB", "CCCCCC", "DDDDDD", "EEEEEE"}},
+		{"SU", "\x1b[2S", []string{"CCCCCC", "DDDDDD", "EEEEEE", "      ", "      "}},
+		{"SD", "\x1b[T", []string{"      ", "AAAAAA", "BBBBBB", "CCCCCC", "DDDDDD"}},
+		{"SU in region", "\x1b[2;4r\x1b[S", []string{"AAAAAA", "CCCCCC", "DDDDDD", "      ", "EEEEEE"}},
+		{"SD in region", "\x1b[2;4r\x1b[T", []string{"AAAAAA", "      ", "BBBBBB", "CCCCCC", "EEEEEE"}},
+	}
+	for _, tt := range tests {
+		buf := components.NewTermBuffer(6, 5)
+		s := NewSystem(events.NewBus(), buf)
+		s.feed([]byte(fill + tt.input))
+		if got := screenRows(buf); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: screen = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCharacterEditing(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"ICH", "abcdef\x1b[1;3H\x1b[2@", "ab  cd"},
+		{"ICH default", "abcdef\x1b[1;3H\x1b[@", "ab cde"},
+		{"ICH past edge", "abcdef\x1b[1;3H\x1b[9@", "ab    "},
+		{"DCH", "abcdef\x1b[1;3H\x1b[2P", "abef  "},
+		{"DCH past edge", "abcdef\x1b[1;3H\x1b[9P", "ab    "},
+		{"ECH", "abcdef\x1b[1;3H\x1b[2X", "ab  ef"},
+		{"ECH past edge", "abcdef\x1b[1;5H\x1b[9X", "abcd  "},
+		{"REP", "x\x1b[3b", "xxxx  "},
+		{"REP default", "ab\x1b[b", "abb   "},
+		{"REP wide", "中\x1b[b", "中\x00中\x00  "},
+		{"REP without a character", "\x1b[3b", "      "},
+	}
+	for _, tt := range tests {
+		buf := components.NewTermBuffer(6, 2)
+		s := NewSystem(events.NewBus(), buf)
+		s.feed([]byte(tt.input))
+		if got := rowText(buf, 0, 6); got != tt.want {
+			t.Errorf("%s: row = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCharacterEditingKeepsCursor(t *testing.T) {
+	buf := components.NewTermBuffer(6, 2)
+	s := NewSystem(events.NewBus(), buf)
+	for _, seq := range []string{"\x1b[2@", "\x1b[2P", "\x1b[2X"} {
+		s.feed([]byte("\x1b[1;3H" + seq))
+		if s.cx != 2 || s.cy != 0 {
+			t.Errorf("%q moved the cursor to (%d,%d)", seq, s.cx, s.cy)
+		}
+	}
+}
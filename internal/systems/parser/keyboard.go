@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"fmt"
+
+	"gost/internal/components"
+)
+
+// -----------------------------------------------------------------------------
+// Kitty Keyboard Protocol (CSI > u, CSI < u, CSI = u, CSI ? u)
+// -----------------------------------------------------------------------------
+
+// Keyboard exposes the keyboard reporting state (kitty flags and
+// modifyOtherKeys) for the input system.
+func (s *System) Keyboard() *components.KeyboardProtocol {
+	return s.keyboard
+}
+
+// keyboardProtocol handles the kitty keyboard sequences ending in 'u'.
+func (s *System) keyboardProtocol(args []int) {
+	switch s.csiPrivate {
+	case '>': // push flags
+		s.keyboard.Push(components.KeyboardFlags(s.argOr(args, 0, 0)))
+	case '<': // pop entries
+		s.keyboard.Pop(max(s.argOr(args, 0, 1), 1))
+	case '=': // set, add or remove flags
+		s.keyboard.Set(components.KeyboardFlags(s.argOr(args, 0, 0)), max(s.argOr(args, 1, 1), 1))
+	case '?': // query
+		s.reply(fmt.Sprintf("\x1b[?%du", s.keyboard.Flags()))
+	}
+}
+
+// -----------------------------------------------------------------------------
+// xterm Key Modifier Options (XTMODKEYS, XTQMODKEYS)
+// -----------------------------------------------------------------------------
+
+// modifyKeys handles CSI > Pp ; Pv m. Only resource 4, modifyOtherKeys, is
+// supported; an omitted value resets it.
+func (s *System) modifyKeys(args []int) {
+	if s.argOr(args, 0, 0) == 4 {
+		s.keyboard.SetModifyOtherKeys(s.argOr(args, 1, 0))
+	}
+}
+
+// queryModifyKeys answers CSI ? 4 m with CSI > 4 ; level m.
+func (s *System) queryModifyKeys(args []int) {
+	if s.argOr(args, 0, 0) == 4 {
+		s.reply(fmt.Sprintf("\x1b[>4;%dm", s.keyboard.ModifyOtherKeys()))
+	}
+}
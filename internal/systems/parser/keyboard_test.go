@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"gost/internal/components"
+	"gost/internal/events"
+)
+
+func TestKeyboardProtocolSequences(t *testing.T) {
+	bus := events.NewBus()
+	replies := bus.Subscribe("pty_write")
+	tb := components.NewTermBuffer(20, 4)
+	s := NewSystem(bus, tb)
+
+	s.feed([]byte("\x1b[>1u\x1b[>11u"))
+	if f := s.Keyboard().Flags(); f != 11 {
+		t.Fatalf("after pushes flags = %d, want 11", f)
+	}
+	s.feed([]byte("\x1b[<u"))
+	if f := s.Keyboard().Flags(); f != 1 {
+		t.Fatalf("after pop flags = %d, want 1", f)
+	}
+	s.feed([]byte("\x1b[=2;2u"))
+	if f := s.Keyboard().Flags(); f != 3 {
+		t.Fatalf("after add flags = %d, want 3", f)
+	}
+
+	s.feed([]byte("\x1b[?u"))
+	select {
+	case r := <-replies:
+		if got := string(r.([]byte)); got != "\x1b[?3u" {
+			t.Fatalf("query reply = %q, want %q", got, "\x1b[?3u")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no reply to CSI ? u")
+	}
+
+	s.feed([]byte("\x1b[?1049h"))
+	if f := s.Keyboard().Flags(); f != 0 {
+		t.Fatalf("alternate screen flags = %d, want 0", f)
+	}
+	s.feed([]byte("\x1b[>8u\x1b[?1049l"))
+	if f := s.Keyboard().Flags(); f != 3 {
+		t.Fatalf("primary flags after leaving alternate screen = %d, want 3", f)
+	}
+}
+
+func TestModifyOtherKeysSequences(t *testing.T) {
+	bus := events.NewBus()
+	replies := bus.Subscribe("pty_write")
+	s := NewSystem(bus, components.NewTermBuffer(20, 4))
+
+	s.feed([]byte("\x1b[>4;2m"))
+	if l := s.Keyboard().ModifyOtherKeys(); l != 2 {
+		t.Fatalf("level = %d, want 2", l)
+	}
+	s.feed([]byte("\x1b[?4m"))
+	select {
+	case r := <-replies:
+		if got := string(r.([]byte)); got != "\x1b[>4;2m" {
+			t.Fatalf("query reply = %q, want %q", got, "\x1b[>4;2m")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no reply to CSI ? 4 m")
+	}
+
+	s.feed([]byte("\x1b[>4m"))
+	if l := s.Keyboard().ModifyOtherKeys(); l != 0 {
+		t.Fatalf("level after reset = %d, want 0", l)
+	}
+	s.feed([]byte("\x1b[>4;1m\x1b[>4n"))
+	if l := s.Keyboard().ModifyOtherKeys(); l != 0 {
+		t.Fatalf("level after disable = %d, want 0", l)
+	}
+	s.feed([]byte("\x1b[>1;2m"))
+	if l := s.Keyboard().ModifyOtherKeys(); l != 0 {
+		t.Fatalf("other resources changed modifyOtherKeys to %d", l)
+	}
+}
@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"gost/internal/components"
+	"gost/internal/events"
+)
+
+func TestDECSTBM(t *testing.T) {
+	tests := []struct {
+		name, input         string
+		top, bottom, cx, cy int
+	}{
+		{"region", "\x1b[3;3H\x1b[2;4r", 1, 3, 0, 0},
+		{"default bottom", "\x1b[2r", 1, 4, 0, 0},
+		{"reset", "\x1b[2;4r\x1b[r", 0, 4, 0, 0},
+		{"bottom past the screen", "\x1b[2;99r", 1, 4, 0, 0},
+		{"top equals bottom", "\x1b[3;3H\x1b[3;3r", 0, 4, 2, 2},
+		{"top below bottom", "\x1b[3;3H\x1b[4;2r", 0, 4, 2, 2},
+		{"origin mode homes to the region", "\x1b[?6h\x1b[2;4r", 1, 3, 0, 1},
+	}
+	for _, tt := range tests {
+		s := NewSystem(events.NewBus(), components.NewTermBuffer(5, 5))
+		s.feed([]byte(tt.input))
+		top, bottom := s.margins()
+		if top != tt.top || bottom != tt.bottom {
+			t.Errorf("%s: margins = %d..%d, want %d..%d", tt.name, top, bottom, tt.top, tt.bottom)
+		}
+		if s.cx != tt.cx || s.cy != tt.cy {
+			t.Errorf("%s: cursor at (%d,%d), want (%d,%d)", tt.name, s.cx, s.cy, tt.cx, tt.cy)
+		}
+	}
+}
+
+func TestIndexAndReverseIndex(t *testing.T) {
+	const fill = "AAAAA\r\nBBBBB\r\nCCCCC\r\nDDDDD\r\nEEEEE"
+	tests := []struct {
+		name, input string
+		want        []string
+		cx, cy      int
+	}{
+		{"IND at the bottom margin", "\x1b[2;4r\x1b[4;3H\x1bD",
+			[]string{"AAAAA", "CCCCC", "DDDDD", "     ", "EEEEE"}, 2, 3},
+		{"IND inside the region", "\x1b[2;4r\x1b[2;3H\x1bD",
+			[]string{"AAAAA", "BBBBB", "CCCCC", "DDDDD", "EEEEE"}, 2, 2},
+		{"IND below the region", "\x1b[2;3r\x1b[5;1H\x1bD",
+			[]string{"AAAAA", "BBBBB", "CCCCC", "DDDDD", "EEEEE"}, 0, 4},
+		{"RI at the top margin", "\x1b[2;4r\x1b[2;3H\x1bM",
+			[]string{"AAAAA", "     ", "BBBBB", "CCCCC", "EEEEE"}, 2, 1},
+		{"RI at the top of the screen", "\x1b[1;1H\x1bM",
+			[]string{"     ", "AAAAA", "BBBBB", "CCCCC", "DDDDD"}, 0, 0},
+		{"RI above the region", "\x1b[3;4r\x1b[1;1H\x1bM",
+			[]string{"AAAAA", "BBBBB", "CCCCC", "DDDDD", "EEEEE"}, 0, 0},
+		{"NEL at the bottom margin", "\x1b[2;4r\x1b[4;3H\x1bE",
+			[]string{"AAAAA", "CCCCC", "DDDDD", "     ", "EEEEE"}, 0, 3},
+	}
+	for _, tt := range tests {
+		buf := components.NewTermBuffer(5, 5)
+		s := NewSystem(events.NewBus(), buf)
+		s.feed([]byte(fill + tt.input))
+		if got := screenRows(buf); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: screen = %q, want %q", tt.name, got, tt.want)
+		}
+		if s.cx != tt.cx || s.cy != tt.cy {
+			t.Errorf("%s: cursor at (%d,%d), want (%d,%d)", tt.name, s.cx, s.cy, tt.cx, tt.cy)
+		}
+	}
+}
+
+func TestOnlyFullScreenScrollsReachScrollback(t *testing.T) {
+	tests := []struct {
+		name, input string
+		pushed      int
+	}{
+		{"newline at the bottom", "\x1b[5;1H\n\n", 2},
+		{"IND at the bottom", "\x1b[5;1H\x1bD", 1},
+		{"SU", "\x1b[3S", 3},
+		{"region", "\x1b[2;4r\x1b[4;1H\n\n\x1b[S", 0},
+		{"region with the top line", "\x1b[1;4r\x1b[4;1H\n", 0},
+		{"DL", "\x1b[1;1H\x1b[2M", 0},
+		{"alternate screen", "\x1b[?1049h\x1b[5;1H\n\x1b[S", 0},
+	}
+	for _, tt := range tests {
+		buf := components.NewTermBuffer(5, 5)
+		sb := components.NewScrollback(100)
+		buf.AttachScrollback(sb)
+		s := NewSystem(events.NewBus(), buf)
+		s.feed([]byte("AAAAA\r\nBBBBB\r\nCCCCC" + tt.input))
+		if n := sb.Count(); n != tt.pushed {
+			t.Errorf("%s: scrollback holds %d lines, want %d", tt.name, n, tt.pushed)
+		}
+	}
+
+	// Lines reach scrollback in screen order.
+	buf := components.NewTermBuffer(5, 2)
+	sb := components.NewScrollback(100)
+	buf.AttachScrollback(sb)
+	s := NewSystem(events.NewBus(), buf)
+	s.feed([]byte("a\r\nb\r\nc\r\nd"))
+	for i, want := range "ab" {
+		if got := sb.GetLine(i)[0].Rune; got != want {
+			t.Errorf("scrollback line %d = %q, want %q", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"fmt"
+
+	"gost/internal/components"
+)
+
+// -----------------------------------------------------------------------------
+// DEC Private Modes (DECSET / DECRST / DECRQM)
+// -----------------------------------------------------------------------------
+
+// Modes exposes the parser's mode table for systems that poll it directly.
+func (s *System) Modes() *components.ModeTable {
+	return s.modes
+}
+
+// setPrivateMode records a DEC private mode and applies its side effects.
+// Other systems poll the mode table rather than being told of changes.
+func (s *System) setPrivateMode(n int, on bool) {
+	mode := components.Mode(n)
+	s.modes.Set(mode, on)
+
+	switch mode {
+	case components.ModeOrigin:
+		s.cx, s.cy = s.home()
+		s.wrapPending = false
+	case components.ModeAltScreen: // no clear, no cursor save
+		if on {
+			s.buffer.EnterAltScreen(false, false)
+		} else {
+			s.buffer.ExitAltScreen(false, false)
+		}
+	case components.ModeAltScreenClear: // cleared when leaving
+		if on {
+			s.buffer.EnterAltScreen(false, false)
+		} else {
+			s.buffer.ExitAltScreen(false, true)
+		}
+	case components.ModeSaveCursor:
+		if on {
+			s.savedX, s.savedY = s.cx, s.cy
+		} else {
+			s.cx, s.cy = s.savedX, s.savedY
+			s.wrapPending = false
+		}
+	case components.ModeAltScreenSave: // save cursor, cleared when entering
+		s.syncCursor()
+		if on {
+			s.buffer.EnterAltScreen(true, true)
+		} else {
+			s.buffer.ExitAltScreen(true, false)
+			s.cx, s.cy = s.buffer.GetCursor()
+			s.wrapPending = false
+		}
+	}
+
+	s.keyboard.SetAltScreen(s.buffer.AltScreenActive())
+}
+
+// requestMode answers DECRQM with DECRPM: CSI [?] n ; state $ y, where
+// state is 0 (not recognized), 1 (set), 2 (reset) or 4 (permanently reset).
+func (s *System) requestMode(n int, private bool) {
+	state := 0
+	prefix := ""
+	if private {
+		prefix = "?"
+		mode := components.Mode(n)
+		switch {
+		case !s.modes.Known(mode):
+			state = 0
+		case s.modes.Enabled(mode):
+			state = 1
+		default:
+			state = 2
+		}
+	} else if n == 4 || n == 20 { // IRM, LNM — not supported
+		state = 4
+	}
+	s.reply(fmt.Sprintf("\x1b[%s%d;%d$y", prefix, n, state))
+}
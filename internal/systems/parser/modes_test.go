@@ -0,0 +1,42 @@
+package parser
+
+import (
+	"testing"
+
+	"gost/internal/components"
+	"gost/internal/events"
+)
+
+func TestDECRQM(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"set by default", "\x1b[?7$p", "\x1b[?7;1$y"},
+		{"reset by default", "\x1b[?2004$p", "\x1b[?2004;2$y"},
+		{"after DECSET", "\x1b[?2004h\x1b[?2004$p", "\x1b[?2004;1$y"},
+		{"after DECRST", "\x1b[?25l\x1b[?25$p", "\x1b[?25;2$y"},
+		{"several at once", "\x1b[?1;6h\x1b[?6$p", "\x1b[?6;1$y"},
+		{"alternate screen", "\x1b[?1049h\x1b[?1049$p", "\x1b[?1049;1$y"},
+		{"unknown private mode", "\x1b[?9999$p", "\x1b[?9999;0$y"},
+		{"IRM", "\x1b[4$p", "\x1b[4;4$y"},
+		{"LNM", "\x1b[20$p", "\x1b[20;4$y"},
+		{"unknown ANSI mode", "\x1b[3$p", "\x1b[3;0$y"},
+	}
+	for _, tt := range tests {
+		bus := events.NewBus()
+		ch := bus.Subscribe("pty_write")
+		s := NewSystem(bus, components.NewTermBuffer(10, 4))
+		got := replies(s, ch, tt.input)
+		if len(got) != 1 || got[0] != tt.want {
+			t.Errorf("%s: replies = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUnknownModeIsNotRecorded(t *testing.T) {
+	s := NewSystem(events.NewBus(), components.NewTermBuffer(10, 4))
+	s.feed([]byte("\x1b[?9999h"))
+	if s.Modes().Enabled(components.Mode(9999)) {
+		t.Fatal("DECSET of an unknown mode was recorded")
+	}
+}
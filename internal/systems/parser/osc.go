@@ -0,0 +1,150 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"gost/internal/components"
+)
+
+// -----------------------------------------------------------------------------
+// OSC (Operating System Command)
+// -----------------------------------------------------------------------------
+
+// maxOSCLen bounds a buffered OSC payload in bytes; longer strings are
+// discarded.
+// It leaves room for a 1 MiB OSC 52 clipboard payload in base64.
+const maxOSCLen = 2 << 20
+
+// maxTitleStack bounds the XTWINOPS title stack, as in xterm.
+const maxTitleStack = 10
+
+// titleEntry is one saved window title / icon name pair.
+type titleEntry struct {
+	title, icon string
+}
+
+// oscRune collects one rune of an OSC string. BEL and ST (ESC \ or the
+// C1 byte 0x9C) terminate it; CAN and SUB cancel it.
+func (s *System) oscRune(r rune) {
+	switch r {
+	case '\x07', '\u009c':
+		s.executeOSC(s.escBuf.String())
+		s.state = stateText
+	case '\x1b':
+		s.state = stateOscEsc
+	case '\x18', '\x1a':
+		s.state = stateText
+	default:
+		if s.escBuf.Len() < maxOSCLen {
+			s.escBuf.WriteRune(r)
+		}
+	}
+}
+
+// oscEscRune handles the rune after an ESC inside an OSC string. Anything
+// but '\' abandons the OSC and starts a new escape sequence.
+func (s *System) oscEscRune(r rune) {
+	if r == '\\' {
+		s.executeOSC(s.escBuf.String())
+		s.state = stateText
+		return
+	}
+	s.state = stateEsc
+	s.advance(r)
+}
+
+// executeOSC dispatches a complete "Ps ; Pt" payload.
+func (s *System) executeOSC(payload string) {
+	if len(payload) >= maxOSCLen {
+		return // truncated; do not act on a partial payload
+	}
+	ps, pt, _ := strings.Cut(payload, ";")
+	code, err := strconv.Atoi(ps)
+	if err != nil {
+		return
+	}
+	switch code {
+	case 0: // icon name and window title
+		s.setIconName(pt)
+		s.setTitle(pt)
+	case 1:
+		s.setIconName(pt)
+	case 2:
+		s.setTitle(pt)
+	case 8:
+		s.setHyperlink(pt)
+	case 52:
+		s.clipboardRequest(pt)
+	default:
+		// unrecognized command
+	}
+}
+
+// setTitle records the window title and announces it on "title_changed".
+func (s *System) setTitle(title string) {
+	s.title = title
+	if s.bus != nil {
+		s.bus.Publish("title_changed", title)
+	}
+}
+
+// setIconName records the icon name; GoST has no icon label to show it on.
+func (s *System) setIconName(name string) {
+	s.iconName = name
+}
+
+// setHyperlink handles OSC 8 ; params ; URI. A non-empty URI opens a link
+// span that applies to every cell printed until an empty URI closes it.
+// Of the colon-separated key=value params only "id" is used.
+func (s *System) setHyperlink(pt string) {
+	params, uri, ok := strings.Cut(pt, ";")
+	if !ok || uri == "" {
+		s.link = s.buffer.OpenLink("", "")
+		return
+	}
+	var id string
+	for _, kv := range strings.Split(params, ":") {
+		if v, ok := strings.CutPrefix(kv, "id="); ok {
+			id = v
+		}
+	}
+	s.link = s.buffer.OpenLink(uri, id)
+}
+
+// clipboardRequest forwards OSC 52 ; Pc ; Pd to the clipboard system, which
+// applies the access policy.
+func (s *System) clipboardRequest(pt string) {
+	sel, data, ok := strings.Cut(pt, ";")
+	if !ok || s.bus == nil {
+		return
+	}
+	s.bus.Publish("osc52_request", components.ClipboardRequest{Selection: sel, Data: data})
+}
+
+// windowOp handles the XTWINOPS title stack: CSI 22 ; Ps t pushes and
+// CSI 23 ; Ps t pops, where Ps selects both (0), the icon name (1) or the
+// window title (2) to restore. Other window operations are ignored.
+func (s *System) windowOp(args []int) {
+	op, which := s.argOr(args, 0, 0), s.argOr(args, 1, 0)
+	icon, title := which == 0 || which == 1, which == 0 || which == 2
+	switch op {
+	case 22:
+		if len(s.titleStack) >= maxTitleStack {
+			s.titleStack = s.titleStack[1:]
+		}
+		s.titleStack = append(s.titleStack, titleEntry{title: s.title, icon: s.iconName})
+	case 23:
+		if len(s.titleStack) == 0 {
+			return
+		}
+		e := s.titleStack[len(s.titleStack)-1]
+		s.titleStack = s.titleStack[:len(s.titleStack)-1]
+		if icon {
+			s.setIconName(e.icon)
+		}
+		if title {
+			s.setTitle(e.title)
+		}
+	}
+}
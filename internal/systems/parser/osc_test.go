@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"gost/internal/components"
+	"gost/internal/events"
+)
+
+func TestOSCTerminators(t *testing.T) {
+	tests := []struct {
+		name, input, title string
+		next               rune // first cell after the sequence
+	}{
+		{"BEL", "\x1b]2;bel\x07X", "bel", 'X'},
+		{"ESC backslash", "\x1b]2;st\x1b\\X", "st", 'X'},
+		{"C1 ST", "\x1b]2;c1\u009cX", "c1", 'X'},
+		{"CAN", "\x1b]2;cancelled\x18X", "", 'X'},
+		{"SUB", "\x1b]2;cancelled\x1aX", "", 'X'},
+		{"new escape", "\x1b]2;abandoned\x1b[1mX", "", 'X'},
+	}
+	for _, tt := range tests {
+		buf := components.NewTermBuffer(10, 2)
+		s := NewSystem(events.NewBus(), buf)
+		s.feed([]byte(tt.input))
+
+		if s.title != tt.title {
+			t.Errorf("%s: title = %q, want %q", tt.name, s.title, tt.title)
+		}
+		if got := buf.GetRune(0, 0).Rune; got != tt.next {
+			t.Errorf("%s: cell 0 = %q, want %q", tt.name, got, tt.next)
+		}
+	}
+}
+
+func TestOSCTitleAndIcon(t *testing.T) {
+	s := NewSystem(events.NewBus(), components.NewTermBuffer(10, 2))
+	s.feed([]byte("\x1b]0;both\x07"))
+	if s.title != "both" || s.iconName != "both" {
+		t.Fatalf("OSC 0: title %q, icon %q", s.title, s.iconName)
+	}
+	s.feed([]byte("\x1b]1;icon\x07\x1b]2;title\x07"))
+	if s.title != "title" || s.iconName != "icon" {
+		t.Fatalf("OSC 1/2: title %q, icon %q", s.title, s.iconName)
+	}
+}
+
+func TestTitleStackOverflow(t *testing.T) {
+	s := NewSystem(events.NewBus(), components.NewTermBuffer(10, 2))
+	// Push more titles than the stack holds; the oldest fall off.
+	n := maxTitleStack + 2
+	for i := 0; i < n; i++ {
+		s.feed([]byte(fmt.Sprintf("\x1b]2;t%d\x07\x1b[22;2t", i)))
+	}
+	s.feed([]byte("\x1b]2;last\x07"))
+
+	for i := n - 1; i >= n-maxTitleStack; i-- {
+		s.feed([]byte("\x1b[23;2t"))
+		if want := fmt.Sprintf("t%d", i); s.title != want {
+			t.Fatalf("pop restored %q, want %q", s.title, want)
+		}
+	}
+	s.feed([]byte("\x1b[23;2t"))
+	if want := fmt.Sprintf("t%d", n-maxTitleStack); s.title != want {
+		t.Fatalf("pop on an empty stack changed the title to %q", s.title)
+	}
+}
+
+func TestOSCLimitCountsBytes(t *testing.T) {
+	s := NewSystem(events.NewBus(), components.NewTermBuffer(10, 2))
+	// Three-byte runes: under the cap in runes, over it in bytes.
+	title := strings.Repeat("€", maxOSCLen/2)
+	s.feed([]byte("\x1b]2;" + title))
+	if n := len(s.escBuf.String()); n > maxOSCLen+utf8.UTFMax {
+		t.Fatalf("buffered %d bytes of OSC payload", n)
+	}
+	s.feed([]byte("\x07"))
+	if s.title != "" {
+		t.Fatalf("oversized OSC set a %d-byte title", len(s.title))
+	}
+
+	title = strings.Repeat("€", 100)
+	s.feed([]byte("\x1b]2;" + title + "\x07"))
+	if s.title != title {
+		t.Fatalf("title = %q, want %q", s.title, title)
+	}
+}
@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"testing"
+
+	"gost/internal/components"
+	"gost/internal/events"
+)
+
+// replies feeds input and returns everything the parser wrote back to the
+// PTY. Replies are published synchronously, so they are queued by the time
+// feed returns.
+func replies(s *System, ch <-chan events.Event, input string) []string {
+	s.feed([]byte(input))
+	var out []string
+	for {
+		select {
+		case r := <-ch:
+			out = append(out, string(r.([]byte)))
+		default:
+			return out
+		}
+	}
+}
+
+func TestDeviceReports(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"DSR status", "\x1b[5n", "\x1b[0n"},
+		{"CPR at home", "\x1b[6n", "\x1b[1;1R"},
+		{"CPR", "\x1b[3;7H\x1b[6n", "\x1b[3;7R"},
+		{"DEC CPR", "\x1b[3;7H\x1b[?6n", "\x1b[?3;7R"},
+		{"CPR with a pending wrap", "\x1b[1;1H" + "0123456789" + "\x1b[6n", "\x1b[1;10R"},
+		{"CPR ignores margins without DECOM", "\x1b[3;5r\x1b[4;2H\x1b[6n", "\x1b[4;2R"},
+		{"CPR relative to DECOM", "\x1b[3;5r\x1b[?6h\x1b[2;2H\x1b[6n", "\x1b[2;2R"},
+		{"DEC CPR relative to DECOM", "\x1b[3;5r\x1b[?6h\x1b[?6n", "\x1b[?1;1R"},
+		{"DA1", "\x1b[c", "\x1b[?62;22c"},
+		{"DA1 with 0", "\x1b[0c", "\x1b[?62;22c"},
+		{"DA2", "\x1b[>c", "\x1b[>1;100;0c"},
+		{"DA2 with 0", "\x1b[>0c", "\x1b[>1;100;0c"},
+		{"XTVERSION", "\x1b[>q", "\x1bP>|GoST 1.0\x1b\\"},
+	}
+	for _, tt := range tests {
+		bus := events.NewBus()
+		ch := bus.Subscribe("pty_write")
+		s := NewSystem(bus, components.NewTermBuffer(10, 6))
+		got := replies(s, ch, tt.input)
+		if len(got) != 1 || got[0] != tt.want {
+			t.Errorf("%s: replies = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUnansweredReports(t *testing.T) {
+	for _, input := range []string{
+		"\x1b[1n",  // unknown DSR
+		"\x1b[1c",  // DA1 with a non-zero parameter
+		"\x1b[>1c", // DA2 with a non-zero parameter
+		"\x1b[>1q", // XTVERSION with a non-zero parameter
+		"\x1b[=c",  // DA3 is not implemented
+	} {
+		bus := events.NewBus()
+		ch := bus.Subscribe("pty_write")
+		s := NewSystem(bus, components.NewTermBuffer(10, 6))
+		if got := replies(s, ch, input); len(got) != 0 {
+			t.Errorf("%q: replies = %q, want none", input, got)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"gost/internal/components"
+	"gost/internal/events"
+)
+
+const (
+	bold      = components.AttrBold
+	dim       = components.AttrDim
+	italic    = components.AttrItalic
+	underline = components.AttrUnderline
+	blink     = components.AttrBlink
+	reverse   = components.AttrReverse
+	hidden    = components.AttrHidden
+	strike    = components.AttrStrike
+)
+
+func TestSGRAttributes(t *testing.T) {
+	tests := []struct {
+		seq  string
+		want components.Attr
+	}{
+		{"1", bold},
+		{"2", dim},
+		{"3", italic},
+		{"4", underline},
+		{"5", blink},
+		{"6", blink},
+		{"7", reverse},
+		{"8", hidden},
+		{"9", strike},
+		{"21", underline},
+		{"1;3;4;7", bold | italic | underline | reverse},
+		{"1;2;22", 0},
+		{"1;3;23", bold},
+		{"3;4;24", italic},
+		{"4;21;24", 0},
+		{"5;25", 0},
+		{"7;27", 0},
+		{"8;28", 0},
+		{"9;29", 0},
+		{"1;2;3;4;5;7;8;9;0", 0},
+		{"1;4;", 0}, // trailing empty parameter reads as 0
+		{"", 0},     // CSI m resets
+		{"1;31;0;3", italic},
+	}
+	for _, tt := range tests {
+		buf := components.NewTermBuffer(4, 1)
+		s := NewSystem(events.NewBus(), buf)
+		s.feed([]byte("\x1b[" + tt.seq + "mx"))
+		if s.attr != tt.want {
+			t.Errorf("CSI %s m: attr = %#x, want %#x", tt.seq, s.attr, tt.want)
+		}
+		if got := buf.GetRune(0, 0).Attr; got != tt.want {
+			t.Errorf("CSI %s m: cell attr = %#x, want %#x", tt.seq, got, tt.want)
+		}
+	}
+}
+
+func TestSGRResetKeepsOtherAttributes(t *testing.T) {
+	s := NewSystem(events.NewBus(), components.NewTermBuffer(4, 1))
+	s.feed([]byte("\x1b[1;2;3;4;5;7;8;9m"))
+	all := bold | dim | italic | underline | blink | reverse | hidden | strike
+	resets := []struct {
+		code  string
+		clear components.Attr
+	}{
+		{"22", bold | dim},
+		{"23", italic},
+		{"24", underline},
+		{"25", blink},
+		{"27", reverse},
+		{"28", hidden},
+		{"29", strike},
+	}
+	for _, r := range resets {
+		s.feed([]byte("\x1b[" + r.code + "m"))
+		all &^= r.clear
+		if s.attr != all {
+			t.Fatalf("after %s: attr = %#x, want %#x", r.code, s.attr, all)
+		}
+	}
+}
+
+func TestSGRColors(t *testing.T) {
+	def := components.ColorDefault
+	idx := components.IndexedColor
+	rgb := components.RGBColor
+	tests := []struct {
+		seq    string
+		fg, bg components.Color
+	}{
+		{"31", idx(1), def},
+		{"44", def, idx(4)},
+		{"92;103", idx(10), idx(11)},
+		{"31;39", def, def},
+		{"41;49", def, def},
+		{"38;5;208", idx(208), def},
+		{"48;5;17", def, idx(17)},
+		{"38;2;10;20;30", rgb(10, 20, 30), def},
+		{"48;2;1;2;3", def, rgb(1, 2, 3)},
+		{"38;2;300;0;30", rgb(255, 0, 30), def},
+		{"38;5;208;1", idx(208), def},
+		{"38;2;1;2;3;44", rgb(1, 2, 3), idx(4)},
+		{"38:5:208", idx(208), def},
+		{"48:5:17", def, idx(17)},
+		{"38:2::10:20:30", rgb(10, 20, 30), def},
+		{"38:2:0:10:20:30", rgb(10, 20, 30), def},
+		{"38:2:10:20:30", rgb(10, 20, 30), def},
+		{"48:2::1:2:3;31", idx(1), rgb(1, 2, 3)},
+		{"38:2::1:2:3;4", rgb(1, 2, 3), def},
+		{"38;5", def, def},         // incomplete
+		{"38;2;1;2", def, def},     // incomplete
+		{"38:2:1:2", def, def},     // incomplete
+		{"38;7;1;31", idx(1), def}, // unknown color kind
+	}
+	for _, tt := range tests {
+		s := NewSystem(events.NewBus(), components.NewTermBuffer(4, 1))
+		s.feed([]byte("\x1b[" + tt.seq + "m"))
+		if s.fg != tt.fg || s.bg != tt.bg {
+			t.Errorf("CSI %s m: fg, bg = %#x, %#x, want %#x, %#x", tt.seq, s.fg, s.bg, tt.fg, tt.bg)
+		}
+	}
+}
+
+func TestSGRSubParameters(t *testing.T) {
+	tests := []struct {
+		seq  string
+		want components.Attr
+	}{
+		{"4:0", 0},
+		{"4:1", underline},
+		{"4:2", underline},
+		{"4:3", underline},
+		{"4:4", underline},
+		{"4:5", underline},
+		{"4;4:0", 0},
+		{"1;4:3;7", bold | underline | reverse},
+		{"38:2::1:2:3;1", bold}, // colon colors do not swallow the next parameter
+	}
+	for _, tt := range tests {
+		s := NewSystem(events.NewBus(), components.NewTermBuffer(4, 1))
+		s.feed([]byte("\x1b[" + tt.seq + "m"))
+		if s.attr != tt.want {
+			t.Errorf("CSI %s m: attr = %#x, want %#x", tt.seq, s.attr, tt.want)
+		}
+	}
+
+	// The colon form is not mistaken for the end of the sequence.
+	buf := components.NewTermBuffer(4, 1)
+	s := NewSystem(events.NewBus(), buf)
+	s.feed([]byte("\x1b[38:2::1:2:3mx"))
+	if g := buf.GetRune(0, 0); g.Rune != 'x' || g.Fg != components.RGBColor(1, 2, 3) {
+		t.Fatalf("cell = %q fg %#x", g.Rune, g.Fg)
+	}
+}
+
+func TestParseSubParams(t *testing.T) {
+	s := NewSystem(events.NewBus(), components.NewTermBuffer(4, 1))
+	got := s.parseSubParams("1;38:2::10:20:30;;4:3")
+	want := [][]int{{1}, {38, 2, 0, 10, 20, 30}, {0}, {4, 3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseSubParams = %v, want %v", got, want)
+	}
+	if got := s.parseSubParams(""); got != nil {
+		t.Fatalf("parseSubParams(\"\") = %v", got)
+	}
+}
@@ -1,10 +1,12 @@
 package parser
 
 import (
+	"fmt"
 	"log"
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"gost/internal/components"
 	"gost/internal/events"
@@ -19,30 +21,59 @@ const (
 	stateEsc
 	stateCSI
 	stateOsc
+	stateOscEsc  // ESC inside an OSC string: ST or a new sequence
+	stateCharset // ESC ( ) * + awaiting the charset final byte
 )
 
 // System consumes PTY output and updates the terminal buffer.
 type System struct {
-	bus    *events.Bus
-	buffer *components.TermBuffer
-	sub    <-chan events.Event
+	bus       *events.Bus
+	buffer    *components.TermBuffer
+	sub       <-chan events.Event
+	resizeSub <-chan events.Event
 
-	state  int
-	escBuf stringBuilder
+	state      int
+	utf8       utf8Decoder
+	escBuf     stringBuilder
+	csiPrivate rune // private marker of the current CSI sequence ('?', '>', …)
+	csiInter   rune // intermediate byte of the current CSI sequence ('$', ' ', …)
 
-	cx, cy         int // cursor position
-	fg, bg         int // current color attributes
-	savedX, savedY int // saved cursor for ESC7/ESC8
+	modes    *components.ModeTable
+	keyboard *components.KeyboardProtocol // kitty keyboard flag stacks
+
+	cx, cy         int              // cursor position
+	fg, bg         components.Color // current color attributes
+	attr           components.Attr  // current SGR attributes
+	savedX, savedY int              // saved cursor for ESC7/ESC8
+
+	marginTop, marginBottom int // scrolling region (DECSTBM); bottom 0 = last row
+
+	lastChar    rune // last printed graphic character, repeated by REP
+	wrapPending bool // cursor sits on the last column; the next print wraps first
+
+	lastX, lastY int  // cell holding the last printed grapheme cluster
+	hasLast      bool // whether lastX/lastY may still be extended
+
+	charsets    [4]charset // G0–G3 designations
+	gl          int        // charset invoked into GL (0 = G0 via SI, 1 = G1 via SO)
+	singleShift int        // G2/G3 selected for the next character only (SS2/SS3)
+	charsetSlot int        // target slot of a pending designation
+
+	title, iconName string       // set by OSC 0/1/2
+	titleStack      []titleEntry // XTWINOPS push/pop
+
+	link uint32 // open OSC 8 hyperlink applied to printed cells, 0 if none
 }
 
 // NewSystem subscribes to PTY output and initializes parser state.
 func NewSystem(bus *events.Bus, tb *components.TermBuffer) *System {
 	ps := &System{
-		bus:    bus,
-		buffer: tb,
-		sub:    bus.Subscribe("pty_output"),
-		fg:     7,
-		bg:     0,
+		bus:       bus,
+		buffer:    tb,
+		sub:       bus.Subscribe("pty_output"),
+		resizeSub: bus.Subscribe("term_resize"),
+		modes:     components.NewModeTable(),
+		keyboard:  components.NewKeyboardProtocol(),
 	}
 	return ps
 }
@@ -52,10 +83,17 @@ func NewSystem(bus *events.Bus, tb *components.TermBuffer) *System {
 // -----------------------------------------------------------------------------
 
 func (s *System) UpdateECS() {
+	select {
+	case evt := <-s.resizeSub:
+		if size, ok := evt.(components.TermSize); ok {
+			s.resize(size.Cols, size.Rows)
+		}
+	default:
+	}
 	select {
 	case evt := <-s.sub:
 		if data, ok := evt.([]byte); ok {
-			s.feed(string(data))
+			s.feed(data)
 		}
 	default:
 	}
@@ -65,59 +103,139 @@ func (s *System) UpdateECS() {
 func (s *System) Reset() {
 	s.state = stateText
 	s.cx, s.cy = 0, 0
-	s.fg, s.bg = 7, 0
+	s.fg, s.bg = components.ColorDefault, components.ColorDefault
+	s.attr = 0
 	s.savedX, s.savedY = 0, 0
+	s.marginTop, s.marginBottom = 0, 0
+	s.wrapPending = false
+	s.link = s.buffer.OpenLink("", "")
+	s.modes.Reset()
+	s.keyboard.Reset()
+	s.resetCharsets()
 	s.escBuf.Reset()
+	s.utf8.reset()
 	log.Println("[Parser] reset state")
 }
 
+// resize re-flows the buffer to cols×rows and picks up the cursor position
+// it was anchored to. The scrolling region is reset, as in xterm.
+func (s *System) resize(cols, rows int) {
+	if cols <= 0 || rows <= 0 || (cols == s.buffer.Width && rows == s.buffer.Height) {
+		return
+	}
+	s.buffer.Resize(cols, rows)
+	s.cx, s.cy = s.buffer.GetCursor()
+	s.savedX, s.savedY = min(s.savedX, cols-1), min(s.savedY, rows-1)
+	s.marginTop, s.marginBottom = 0, 0
+	s.wrapPending, s.hasLast = false, false
+	log.Printf("[Parser] resized to %dx%d", cols, rows)
+}
+
 // -----------------------------------------------------------------------------
 // Input Feed
 // -----------------------------------------------------------------------------
 
-func (s *System) feed(input string) {
-	for _, r := range input {
-		switch s.state {
-		case stateText:
-			switch r {
-			case '\x1b':
-				s.state = stateEsc
-			default:
-				s.putChar(r)
-			}
-		case stateEsc:
-			switch r {
-			case '[':
-				s.state = stateCSI
-				s.escBuf.Reset()
-			case ']':
-				s.state = stateOsc
-				s.escBuf.Reset()
-			case '7': // Save cursor
-				s.savedX, s.savedY = s.cx, s.cy
-				s.state = stateText
-			case '8': // Restore cursor
-				s.cx, s.cy = s.savedX, s.savedY
-				s.clipCursor()
-				s.syncCursor()
-				s.state = stateText
-			default:
-				s.state = stateText
-			}
-		case stateCSI:
-			if (r >= '0' && r <= '9') || r == ';' {
-				s.escBuf.WriteRune(r)
-				continue
-			}
-			s.executeCSI(r)
+// feed decodes a raw PTY chunk; partial UTF-8 sequences carry over to the
+// next call. Cluster and link ids the chunk left unused are then reclaimed.
+func (s *System) feed(data []byte) {
+	s.utf8.decode(data, s.advance)
+	s.buffer.Collect()
+}
+
+// advance steps the state machine by one rune.
+func (s *System) advance(r rune) {
+	switch s.state {
+	case stateText:
+		switch r {
+		case '\x1b':
+			s.state = stateEsc
+			s.hasLast = false
+		default:
+			s.putChar(r)
+		}
+	case stateEsc:
+		switch r {
+		case '[':
+			s.state = stateCSI
+			s.escBuf.Reset()
+			s.csiPrivate, s.csiInter = 0, 0
+		case ']':
+			s.state = stateOsc
+			s.escBuf.Reset()
+		case '7': // Save cursor
+			s.savedX, s.savedY = s.cx, s.cy
 			s.state = stateText
-		case stateOsc:
-			if r == '\x07' { // BEL terminates OSC
-				s.state = stateText
-			} else {
-				s.escBuf.WriteRune(r)
-			}
+		case '8': // Restore cursor
+			s.wrapPending = false
+			s.cx, s.cy = s.savedX, s.savedY
+			s.clipCursor()
+			s.syncCursor()
+			s.state = stateText
+		case 'D': // IND — index
+			s.wrapPending = false
+			s.index()
+			s.syncCursor()
+			s.state = stateText
+		case 'E': // NEL — next line
+			s.wrapPending = false
+			s.cx = 0
+			s.index()
+			s.syncCursor()
+			s.state = stateText
+		case 'M': // RI — reverse index
+			s.wrapPending = false
+			s.reverseIndex()
+			s.syncCursor()
+			s.state = stateText
+		case '=': // DECKPAM — application keypad
+			s.setPrivateMode(int(components.ModeKeypad), true)
+			s.state = stateText
+		case '>': // DECKPNM — numeric keypad
+			s.setPrivateMode(int(components.ModeKeypad), false)
+			s.state = stateText
+		case 'H': // HTS — set tab stop at the cursor column
+			s.buffer.SetTabStop(s.cx)
+			s.state = stateText
+		case '(', ')', '*', '+': // SCS — designate G0–G3
+			s.charsetSlot = int(r - '(')
+			s.state = stateCharset
+		case 'N': // SS2 — single shift G2
+			s.singleShift = 2
+			s.state = stateText
+		case 'O': // SS3 — single shift G3
+			s.singleShift = 3
+			s.state = stateText
+		case 'n': // LS2 — lock shift G2
+			s.gl = 2
+			s.state = stateText
+		case 'o': // LS3 — lock shift G3
+			s.gl = 3
+			s.state = stateText
+		default:
+			s.state = stateText
+		}
+	case stateCSI:
+		if (r >= '0' && r <= '9') || r == ';' || r == ':' {
+			s.escBuf.WriteRune(r)
+			return
+		}
+		if r >= '<' && r <= '?' && s.escBuf.Len() == 0 && s.csiPrivate == 0 {
+			s.csiPrivate = r
+			return
 		}
+		if r >= ' ' && r <= '/' {
+			s.csiInter = r
+			return
+		}
+		s.executeCSI(r)
+		s.state = stateText
+	case stateCharset:
+		s.designate(s.charsetSlot, r)
+		s.state = stateText
+	case stateOsc:
+		s.oscRune(r)
+	case stateOscEsc:
+		s.oscEscRune(r)
 	}
 }
 
@@ -126,44 +244,154 @@ func (s *System) feed(input string) {
 // -----------------------------------------------------------------------------
 
 func (s *System) putChar(r rune) {
+	if r < ' ' {
+		s.hasLast = false
+	}
+	switch r {
+	case '\r', '\n', '\b', '\t':
+		s.wrapPending = false
+	}
 	switch r {
 	case '\r': // carriage return
 		s.cx = 0
 	case '\n': // newline
-		s.cy++
-		if s.cy >= s.buffer.Height {
-			s.buffer.ScrollUp()
-			s.cy = s.buffer.Height - 1
-		}
+		s.index()
+	case '\x0e': // SO — invoke G1
+		s.gl = 1
+	case '\x0f': // SI — invoke G0
+		s.gl = 0
+	case '\t': // horizontal tab
+		s.cx = s.buffer.NextTabStop(s.cx, 1)
 	case '\b': // backspace
 		if s.cx > 0 {
 			s.cx--
 			s.buffer.SetRune(s.cx, s.cy, ' ', s.fg, s.bg)
 		}
 	default:
-		if unicode.IsPrint(r) {
-			if s.cx >= s.buffer.Width {
-				s.cx = 0
-				s.cy++
-				if s.cy >= s.buffer.Height {
-					s.buffer.ScrollUp()
-					s.cy = s.buffer.Height - 1
-				}
-			}
-			s.buffer.SetRune(s.cx, s.cy, r, s.fg, s.bg)
-			s.cx++
+		if unicode.IsPrint(r) || isZeroWidth(r) {
+			s.printRune(s.translateCharset(r))
 		}
 	}
 	s.clipCursor()
 	s.syncCursor()
 }
 
+// printRune places a graphic character at the cursor. Wide characters take
+// a leading cell plus a continuation cell; characters continuing a grapheme
+// cluster are appended to the previous cell instead.
+func (s *System) printRune(r rune) {
+	if s.joinsCluster(r) {
+		s.extendCluster(r)
+		return
+	}
+	width := runeWidth(r)
+	if width == 0 {
+		return // nothing to attach to
+	}
+	if s.wrapPending || s.cx+width > s.buffer.Width {
+		if s.modes.Enabled(components.ModeAutoWrap) {
+			s.buffer.SetWrapped(s.cy, true)
+			s.cx = 0
+			s.index()
+		} else {
+			s.cx = max(s.buffer.Width-width, 0)
+		}
+	}
+	s.wrapPending = false
+
+	g := components.Glyph{Rune: r, Fg: s.fg, Bg: s.bg, Attr: s.attr, Link: s.link}
+	if width == 2 {
+		g.Flags = components.CellWide
+		s.buffer.SetGlyph(s.cx, s.cy, g)
+		s.buffer.SetGlyph(s.cx+1, s.cy, components.Glyph{
+			Fg: s.fg, Bg: s.bg, Attr: s.attr, Flags: components.CellWideCont, Link: s.link,
+		})
+	} else {
+		s.buffer.SetGlyph(s.cx, s.cy, g)
+	}
+	s.lastX, s.lastY, s.hasLast = s.cx, s.cy, true
+	s.advanceCursor(s.cx + width)
+	s.lastChar = r
+}
+
+// advanceCursor moves the cursor to column next after printing. Reaching the
+// right edge leaves the cursor on the last column with a wrap pending, so the
+// line only wraps if another character is printed.
+func (s *System) advanceCursor(next int) {
+	if next < s.buffer.Width {
+		s.cx = next
+		return
+	}
+	s.cx = s.buffer.Width - 1
+	s.wrapPending = s.modes.Enabled(components.ModeAutoWrap)
+}
+
+// joinsCluster reports whether r continues the grapheme cluster in the last
+// printed cell: combining marks and other zero-width characters, emoji after
+// a ZWJ, skin-tone modifiers, and the second half of a flag pair.
+func (s *System) joinsCluster(r rune) bool {
+	if !s.hasLast {
+		return false
+	}
+	if runeWidth(r) == 0 {
+		return true
+	}
+	prev := s.buffer.GetRune(s.lastX, s.lastY)
+	last := prev.LastRune()
+	switch {
+	case last == zeroWidthJoiner:
+		return true
+	case isEmojiModifier(r):
+		return prev.IsWide()
+	case isRegionalIndicator(r):
+		return isRegionalIndicator(last) && prev.RuneCount() == 1
+	}
+	return false
+}
+
+// extendCluster appends r to the last printed cell. A flag pair or an emoji
+// presentation selector turns a narrow cell into a wide one.
+func (s *System) extendCluster(r rune) {
+	s.buffer.AppendRune(s.lastX, s.lastY, r)
+
+	g := s.buffer.GetRune(s.lastX, s.lastY)
+	if g.IsWide() || (r != emojiPresentation && !isRegionalIndicator(r)) {
+		return
+	}
+	if s.lastX+1 >= s.buffer.Width {
+		return
+	}
+	g.Flags |= components.CellWide
+	s.buffer.SetGlyph(s.lastX, s.lastY, g)
+	s.buffer.SetGlyph(s.lastX+1, s.lastY, components.Glyph{
+		Fg: g.Fg, Bg: g.Bg, Attr: g.Attr, Flags: components.CellWideCont, Link: g.Link,
+	})
+	if s.cy == s.lastY && s.cx <= s.lastX+1 {
+		s.advanceCursor(s.lastX + 2)
+	}
+}
+
 // -----------------------------------------------------------------------------
 // CSI (Control Sequence Introducer) Commands
 // -----------------------------------------------------------------------------
 
+// cancelsWrap lists the CSI finals that move the cursor or edit the line
+// under it, and so cancel a pending wrap. SGR, REP, reports, mode changes
+// and the like leave it set, so the next character still wraps.
+const cancelsWrap = "ABCDHfJKLM@PIZr"
+
 func (s *System) executeCSI(final rune) {
+	s.hasLast = false
 	args := s.parseArgs(s.escBuf.String())
+	if s.csiPrivate != 0 || s.csiInter != 0 {
+		s.executePrivateCSI(final, args)
+		s.clipCursor()
+		s.syncCursor()
+		return
+	}
+	if strings.ContainsRune(cancelsWrap, final) {
+		s.wrapPending = false
+	}
 	switch final {
 	case 'A': // Cursor Up
 		n := s.argOr(args, 0, 1)
@@ -181,6 +409,10 @@ func (s *System) executeCSI(final rune) {
 		y := s.argOr(args, 0, 1) - 1
 		x := s.argOr(args, 1, 1) - 1
 		s.cx, s.cy = x, y
+		if s.modes.Enabled(components.ModeOrigin) {
+			top, bottom := s.margins()
+			s.cy = min(max(y+top, top), bottom)
+		}
 	case 'J': // Erase in Display
 		s.eraseDisplay(s.argOr(args, 0, 0))
 	case 'K': // Erase in Line
@@ -194,7 +426,46 @@ func (s *System) executeCSI(final rune) {
 			s.eraseFullLine()
 		}
 	case 'm': // SGR (Select Graphic Rendition)
-		s.applySGR(args)
+		s.applySGR(s.parseSubParams(s.escBuf.String()))
+	case 'L': // IL — Insert Line
+		s.insertLines(s.argOr(args, 0, 1))
+	case 'M': // DL — Delete Line
+		s.deleteLines(s.argOr(args, 0, 1))
+	case '@': // ICH — Insert Character
+		s.buffer.InsertChars(s.cx, s.cy, max(s.argOr(args, 0, 1), 1))
+	case 'P': // DCH — Delete Character
+		s.buffer.DeleteChars(s.cx, s.cy, max(s.argOr(args, 0, 1), 1))
+	case 'X': // ECH — Erase Character
+		s.eraseChars(max(s.argOr(args, 0, 1), 1))
+	case 'S': // SU — Scroll Up
+		top, bottom := s.margins()
+		s.buffer.ScrollRegionUp(top, bottom, max(s.argOr(args, 0, 1), 1))
+	case 'T': // SD — Scroll Down
+		top, bottom := s.margins()
+		s.buffer.ScrollRegionDown(top, bottom, max(s.argOr(args, 0, 1), 1))
+	case 'b': // REP — Repeat preceding graphic character
+		s.repeatLastChar(max(s.argOr(args, 0, 1), 1))
+	case 'n': // DSR — Device Status Report
+		s.deviceStatus(s.argOr(args, 0, 0), false)
+	case 'c': // DA1 — Primary Device Attributes
+		if s.argOr(args, 0, 0) == 0 {
+			s.reply(primaryDA)
+		}
+	case 'I': // CHT — Cursor Forward Tabulation
+		s.cx = s.buffer.NextTabStop(s.cx, max(s.argOr(args, 0, 1), 1))
+	case 'Z': // CBT — Cursor Backward Tabulation
+		s.cx = s.buffer.PrevTabStop(s.cx, max(s.argOr(args, 0, 1), 1))
+	case 'g': // TBC — Tab Clear
+		switch s.argOr(args, 0, 0) {
+		case 0:
+			s.buffer.ClearTabStop(s.cx)
+		case 3:
+			s.buffer.ClearAllTabStops()
+		}
+	case 'r': // DECSTBM — Set Top and Bottom Margins
+		s.setMargins(s.argOr(args, 0, 1), s.argOr(args, 1, s.buffer.Height))
+	case 't': // XTWINOPS — title stack
+		s.windowOp(args)
 	default:
 		// unrecognized sequence
 	}
@@ -202,41 +473,202 @@ func (s *System) executeCSI(final rune) {
 	s.syncCursor()
 }
 
+// executePrivateCSI handles sequences carrying a private marker (CSI ? …)
+// or an intermediate byte (CSI … $ p).
+func (s *System) executePrivateCSI(final rune, args []int) {
+	switch {
+	case s.csiInter == '$' && final == 'p': // DECRQM — Request Mode
+		s.requestMode(s.argOr(args, 0, 0), s.csiPrivate == '?')
+	case s.csiInter != 0:
+		// unrecognized intermediate sequence
+	case s.csiPrivate == '?' && (final == 'h' || final == 'l'): // DECSET / DECRST
+		for _, mode := range args {
+			s.setPrivateMode(mode, final == 'h')
+		}
+	case s.csiPrivate == '?' && final == 'n': // DECDSR
+		s.deviceStatus(s.argOr(args, 0, 0), true)
+	case s.csiPrivate == '>' && final == 'c': // DA2 — Secondary Device Attributes
+		if s.argOr(args, 0, 0) == 0 {
+			s.reply(secondaryDA)
+		}
+	case final == 'u' && s.csiPrivate != 0: // kitty keyboard protocol
+		s.keyboardProtocol(args)
+	case s.csiPrivate == '>' && final == 'm': // XTMODKEYS
+		s.modifyKeys(args)
+	case s.csiPrivate == '>' && final == 'n': // XTMODKEYS — disable
+		if s.argOr(args, 0, 0) == 4 {
+			s.keyboard.SetModifyOtherKeys(0)
+		}
+	case s.csiPrivate == '?' && final == 'm': // XTQMODKEYS
+		s.queryModifyKeys(args)
+	case s.csiPrivate == '>' && final == 'q': // XTVERSION
+		if s.argOr(args, 0, 0) == 0 {
+			s.reply("\x1bP>|" + TerminalName + " " + TerminalVersion + "\x1b\\")
+		}
+	default:
+		// unrecognized private sequence
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Reports (replies written back to the PTY)
+// -----------------------------------------------------------------------------
+
+// Terminal identification used by XTVERSION and DA2.
+const (
+	TerminalName    = "GoST"
+	TerminalVersion = "1.0"
+
+	primaryDA   = "\x1b[?62;22c"   // VT220 with ANSI color
+	secondaryDA = "\x1b[>1;100;0c" // VT220, firmware version 1.0.0
+)
+
+// deviceStatus answers DSR 5 (operating status) and DSR 6 (cursor position).
+// The DEC form (CSI ? 6 n) replies with a '?' marker.
+func (s *System) deviceStatus(code int, dec bool) {
+	switch code {
+	case 5:
+		s.reply("\x1b[0n")
+	case 6:
+		prefix := ""
+		if dec {
+			prefix = "?"
+		}
+		row := s.cy
+		if s.modes.Enabled(components.ModeOrigin) {
+			top, _ := s.margins()
+			row -= top
+		}
+		s.reply(fmt.Sprintf("\x1b[%s%d;%dR", prefix, row+1, s.cx+1))
+	}
+}
+
+// reply publishes a response for the PTY system to write to the shell.
+func (s *System) reply(seq string) {
+	if s.bus != nil {
+		s.bus.Publish("pty_write", []byte(seq))
+	}
+}
+
 // -----------------------------------------------------------------------------
 // SGR (Select Graphic Rendition)
 // -----------------------------------------------------------------------------
 
-func (s *System) applySGR(args []int) {
-	if len(args) == 0 {
-		s.fg, s.bg = 7, 0
+// applySGR takes parameters with their colon-separated sub-parameters, so
+// both "38;2;r;g;b" and "38:2::r:g:b" forms are understood.
+func (s *System) applySGR(params [][]int) {
+	if len(params) == 0 {
+		s.resetSGR()
 		return
 	}
-	for i := 0; i < len(args); i++ {
-		code := args[i]
+	for i := 0; i < len(params); i++ {
+		code := params[i][0]
 		switch {
 		case code == 0:
-			s.fg, s.bg = 7, 0
+			s.resetSGR()
+		case code == 4 && len(params[i]) > 1: // 4:x underline style — all drawn single
+			if params[i][1] == 0 {
+				s.attr &^= components.AttrUnderline
+			} else {
+				s.attr |= components.AttrUnderline
+			}
+		case code >= 1 && code <= 9:
+			s.attr |= sgrAttrs[code]
+		case code == 21: // doubly underlined — drawn as single underline
+			s.attr |= components.AttrUnderline
+		case code == 22:
+			s.attr &^= components.AttrBold | components.AttrDim
+		case code >= 23 && code <= 29:
+			s.attr &^= sgrAttrs[code-20]
 		case code >= 30 && code <= 37:
-			s.fg = code - 30
+			s.fg = components.IndexedColor(code - 30)
 		case code >= 40 && code <= 47:
-			s.bg = code - 40
+			s.bg = components.IndexedColor(code - 40)
+		case code >= 90 && code <= 97:
+			s.fg = components.IndexedColor(code - 90 + 8)
+		case code >= 100 && code <= 107:
+			s.bg = components.IndexedColor(code - 100 + 8)
 		case code == 39:
-			s.fg = 7
+			s.fg = components.ColorDefault
 		case code == 49:
-			s.bg = 0
+			s.bg = components.ColorDefault
 		case code == 38 || code == 48:
-			if i+2 < len(args) && args[i+1] == 5 {
-				if code == 38 {
-					s.fg = args[i+2]
-				} else {
-					s.bg = args[i+2]
+			var c components.Color
+			var ok bool
+			if sub := params[i][1:]; len(sub) > 0 {
+				// Colon form; "2:cs:r:g:b" carries a color-space id we skip.
+				if sub[0] == 2 && len(sub) >= 5 {
+					sub = append([]int{2}, sub[2:]...)
+				}
+				c, _, ok = extendedColor(sub)
+			} else {
+				rest := make([]int, 0, 4)
+				for _, p := range params[i+1 : min(i+5, len(params))] {
+					rest = append(rest, p[0])
 				}
-				i += 2
+				var n int
+				c, n, ok = extendedColor(rest)
+				i += n
+			}
+			if !ok {
+				continue
+			}
+			if code == 38 {
+				s.fg = c
+			} else {
+				s.bg = c
 			}
 		}
 	}
 }
 
+func (s *System) resetSGR() {
+	s.fg, s.bg = components.ColorDefault, components.ColorDefault
+	s.attr = 0
+}
+
+// extendedColor decodes the arguments following 38/48: "5;n" or "2;r;g;b".
+// It returns the color and how many arguments were consumed.
+func extendedColor(args []int) (components.Color, int, bool) {
+	if len(args) == 0 {
+		return components.ColorDefault, 0, false
+	}
+	switch args[0] {
+	case 5:
+		if len(args) >= 2 {
+			return components.IndexedColor(args[1]), 2, true
+		}
+	case 2:
+		if len(args) >= 4 {
+			return components.RGBColor(clamp8(args[1]), clamp8(args[2]), clamp8(args[3])), 4, true
+		}
+	}
+	return components.ColorDefault, 0, false
+}
+
+func clamp8(v int) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
+
+// sgrAttrs maps SGR codes 1–9 to attribute bits; code+20 clears them.
+var sgrAttrs = [10]components.Attr{
+	1: components.AttrBold,
+	2: components.AttrDim,
+	3: components.AttrItalic,
+	4: components.AttrUnderline,
+	5: components.AttrBlink,
+	6: components.AttrBlink, // rapid blink
+	7: components.AttrReverse,
+	8: components.AttrHidden,
+	9: components.AttrStrike,
+}
+
 // -----------------------------------------------------------------------------
 // Helpers
 // -----------------------------------------------------------------------------
@@ -248,6 +680,10 @@ func (s *System) parseArgs(seq string) []int {
 	parts := strings.Split(seq, ";")
 	args := make([]int, 0, len(parts))
 	for _, p := range parts {
+		// Sub-parameters only matter to SGR; keep the leading value here.
+		if i := strings.IndexByte(p, ':'); i >= 0 {
+			p = p[:i]
+		}
 		if p == "" {
 			args = append(args, 0)
 			continue
@@ -259,6 +695,25 @@ func (s *System) parseArgs(seq string) []int {
 	return args
 }
 
+// parseSubParams splits a CSI parameter string into parameters and their
+// colon-separated sub-parameters. Empty fields read as 0.
+func (s *System) parseSubParams(seq string) [][]int {
+	if seq == "" {
+		return nil
+	}
+	parts := strings.Split(seq, ";")
+	params := make([][]int, 0, len(parts))
+	for _, p := range parts {
+		fields := strings.Split(p, ":")
+		sub := make([]int, len(fields))
+		for i, f := range fields {
+			sub[i], _ = strconv.Atoi(f)
+		}
+		params = append(params, sub)
+	}
+	return params
+}
+
 func (s *System) argOr(args []int, idx, def int) int {
 	if idx < len(args) {
 		return args[idx]
@@ -266,6 +721,109 @@ func (s *System) argOr(args []int, idx, def int) int {
 	return def
 }
 
+// -----------------------------------------------------------------------------
+// Scrolling Region
+// -----------------------------------------------------------------------------
+
+// margins returns the effective scrolling region as 0-based inclusive rows.
+func (s *System) margins() (int, int) {
+	top, bottom := s.marginTop, s.marginBottom
+	if bottom <= 0 || bottom >= s.buffer.Height {
+		bottom = s.buffer.Height - 1
+	}
+	if top < 0 || top >= bottom {
+		top = 0
+	}
+	return top, bottom
+}
+
+// setMargins applies DECSTBM with 1-based rows and homes the cursor.
+func (s *System) setMargins(top, bottom int) {
+	if top < 1 {
+		top = 1
+	}
+	if bottom < 1 || bottom > s.buffer.Height {
+		bottom = s.buffer.Height
+	}
+	if top >= bottom {
+		return
+	}
+	s.marginTop, s.marginBottom = top-1, bottom-1
+	s.cx, s.cy = s.home()
+}
+
+// home returns the cursor home position, honouring origin mode (DECOM).
+func (s *System) home() (int, int) {
+	if s.modes.Enabled(components.ModeOrigin) {
+		top, _ := s.margins()
+		return 0, top
+	}
+	return 0, 0
+}
+
+// index moves the cursor down one line, scrolling the region at its bottom.
+func (s *System) index() {
+	top, bottom := s.margins()
+	switch {
+	case s.cy == bottom:
+		s.buffer.ScrollRegionUp(top, bottom, 1)
+	case s.cy < s.buffer.Height-1:
+		s.cy++
+	}
+}
+
+// reverseIndex moves the cursor up one line, scrolling the region at its top.
+func (s *System) reverseIndex() {
+	top, bottom := s.margins()
+	switch {
+	case s.cy == top:
+		s.buffer.ScrollRegionDown(top, bottom, 1)
+	case s.cy > 0:
+		s.cy--
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Line & Character Editing
+// -----------------------------------------------------------------------------
+
+// insertLines implements IL; it is ignored outside the scrolling region.
+func (s *System) insertLines(n int) {
+	top, bottom := s.margins()
+	if s.cy < top || s.cy > bottom {
+		return
+	}
+	s.buffer.InsertLines(s.cy, bottom, max(n, 1))
+	s.cx = 0
+}
+
+// deleteLines implements DL; it is ignored outside the scrolling region.
+func (s *System) deleteLines(n int) {
+	top, bottom := s.margins()
+	if s.cy < top || s.cy > bottom {
+		return
+	}
+	s.buffer.DeleteLines(s.cy, bottom, max(n, 1))
+	s.cx = 0
+}
+
+// eraseChars implements ECH: blank n cells from the cursor without moving it.
+func (s *System) eraseChars(n int) {
+	for x := s.cx; x < s.cx+n && x < s.buffer.Width; x++ {
+		s.buffer.SetRune(x, s.cy, ' ', s.fg, s.bg)
+	}
+}
+
+// repeatLastChar implements REP by printing the last graphic character again.
+func (s *System) repeatLastChar(n int) {
+	if s.lastChar == 0 {
+		return
+	}
+	for i := 0; i < n; i++ {
+		s.putChar(s.lastChar)
+	}
+}
+
 // -----------------------------------------------------------------------------
 // Erase & Cursor Management
 // -----------------------------------------------------------------------------
@@ -340,9 +898,11 @@ func (s *System) syncCursor() {
 // Lightweight String Builder
 // -----------------------------------------------------------------------------
 
-type stringBuilder struct{ buf []rune }
+// stringBuilder holds UTF-8 bytes; Len is in bytes.
+type stringBuilder struct{ buf []byte }
 
 func (b *stringBuilder) Reset()           { b.buf = b.buf[:0] }
-func (b *stringBuilder) WriteRune(r rune) { b.buf = append(b.buf, r) }
+func (b *stringBuilder) WriteRune(r rune) { b.buf = utf8.AppendRune(b.buf, r) }
 func (b *stringBuilder) String() string   { return string(b.buf) }
+func (b *stringBuilder) Len() int         { return len(b.buf) }
 
@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"gost/internal/components"
+	"gost/internal/events"
+)
+
+func TestTabStops(t *testing.T) {
+	tests := []struct {
+		name, input string
+		cx          int
+	}{
+		{"default", "\t", 8},
+		{"default twice", "\t\t", 16},
+		{"past the last stop", "\t\t\t", 19},
+		{"from a stop", "\x1b[1;9H\t", 16},
+		{"HTS", "\x1b[1;4H\x1bH\r\t", 3},
+		{"TBC 0", "\x1b[1;9H\x1b[g\r\t", 16},
+		{"TBC default is 0", "\x1b[1;9H\x1b[0g\r\t", 16},
+		{"TBC 0 off a stop", "\x1b[1;5H\x1b[g\r\t", 8},
+		{"TBC 3", "\x1b[3g\r\t", 19},
+		{"TBC 3 then HTS", "\x1b[3g\x1b[1;6H\x1bH\r\t\t", 19},
+		{"CHT", "\x1b[2I", 16},
+		{"CHT default", "\x1b[I", 8},
+		{"CBT", "\x1b[1;18H\x1b[Z", 16},
+		{"CBT 2", "\x1b[1;18H\x1b[2Z", 8},
+		{"CBT at column 0", "\x1b[Z", 0},
+		{"CBT past the first stop", "\x1b[1;10H\x1b[5Z", 0},
+	}
+	for _, tt := range tests {
+		s := NewSystem(events.NewBus(), components.NewTermBuffer(20, 2))
+		s.feed([]byte(tt.input))
+		if s.cx != tt.cx {
+			t.Errorf("%s: cursor column = %d, want %d", tt.name, s.cx, tt.cx)
+		}
+	}
+}
+
+func TestTabStopsAfterResize(t *testing.T) {
+	buf := components.NewTermBuffer(20, 2)
+	s := NewSystem(events.NewBus(), buf)
+	// Custom stop at 3, default stop at 8 cleared.
+	s.feed([]byte("\x1b[1;4H\x1bH\x1b[1;9H\x1b[g\r"))
+
+	s.resize(40, 2)
+	var stops []int
+	for x := 0; x < 39; {
+		x = buf.NextTabStop(x, 1)
+		stops = append(stops, x)
+	}
+	if want := []int{3, 16, 24, 32, 39}; !reflect.DeepEqual(stops, want) {
+		t.Fatalf("stops after widening = %v, want %v", stops, want)
+	}
+
+	// Columns dropped by narrowing come back with default stops.
+	s.resize(10, 2)
+	if got := buf.NextTabStop(3, 1); got != 9 {
+		t.Fatalf("next stop in 10 columns = %d, want the last column", got)
+	}
+	s.resize(30, 2)
+	if got := buf.NextTabStop(9, 1); got != 16 {
+		t.Fatalf("next stop after re-widening = %d, want 16", got)
+	}
+	if got := buf.NextTabStop(3, 1); got != 16 {
+		t.Fatalf("cleared stop at 8 came back: next stop = %d", got)
+	}
+}
@@ -0,0 +1,57 @@
+package parser
+
+import "unicode/utf8"
+
+// -----------------------------------------------------------------------------
+// Incremental UTF-8 Decoder
+// -----------------------------------------------------------------------------
+
+// utf8Decoder turns a stream of PTY chunks into runes. A multibyte sequence
+// split across two reads is held back until the rest of it arrives, instead
+// of decoding each half to U+FFFD.
+type utf8Decoder struct {
+	pending [utf8.UTFMax]byte
+	n       int
+}
+
+// decode emits every complete rune in data, carrying an incomplete trailing
+// sequence over to the next call. Invalid bytes are emitted as U+FFFD.
+func (d *utf8Decoder) decode(data []byte, emit func(rune)) {
+	// Complete a sequence left over from the previous chunk first.
+	for d.n > 0 && len(data) > 0 {
+		d.pending[d.n] = data[0]
+		d.n++
+		data = data[1:]
+		if !utf8.FullRune(d.pending[:d.n]) {
+			continue
+		}
+		r, size := utf8.DecodeRune(d.pending[:d.n])
+		emit(r)
+		// An invalid lead byte consumes only itself; replay the rest.
+		rest := append([]byte(nil), d.pending[size:d.n]...)
+		d.n = 0
+		if len(rest) > 0 {
+			data = append(rest, data...)
+		}
+	}
+
+	for len(data) > 0 {
+		if data[0] < utf8.RuneSelf {
+			emit(rune(data[0]))
+			data = data[1:]
+			continue
+		}
+		if !utf8.FullRune(data) {
+			d.n = copy(d.pending[:], data)
+			return
+		}
+		r, size := utf8.DecodeRune(data)
+		emit(r)
+		data = data[size:]
+	}
+}
+
+// reset drops any partially received sequence.
+func (d *utf8Decoder) reset() {
+	d.n = 0
+}
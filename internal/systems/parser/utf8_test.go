@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"testing"
+
+	"gost/internal/components"
+	"gost/internal/events"
+)
+
+var utf8Cases = []struct {
+	name string
+	in   string
+	want []rune
+}{
+	{"ascii", "abc", []rune("abc")},
+	{"two-byte", "héllo", []rune("héllo")},
+	{"three-byte", "中文", []rune("中文")},
+	{"four-byte", "a😀b", []rune("a😀b")},
+	{"mixed", "ö中😀x", []rune("ö中😀x")},
+	{"invalid lead byte", "a\xffb", []rune{'a', '�', 'b'}},
+	{"truncated then ascii", "\xe4\xb8z", []rune{'�', '�', 'z'}},
+	{"stray continuation", "\x80é", []rune{'�', 'é'}},
+}
+
+func TestUTF8DecoderByteByByte(t *testing.T) {
+	for _, tc := range utf8Cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var d utf8Decoder
+			var got []rune
+			for i := 0; i < len(tc.in); i++ {
+				d.decode([]byte{tc.in[i]}, func(r rune) { got = append(got, r) })
+			}
+			if string(got) != string(tc.want) {
+				t.Fatalf("got %q, want %q", string(got), string(tc.want))
+			}
+		})
+	}
+}
+
+func TestUTF8DecoderSplitPoints(t *testing.T) {
+	for _, tc := range utf8Cases {
+		for split := 0; split <= len(tc.in); split++ {
+			var d utf8Decoder
+			var got []rune
+			emit := func(r rune) { got = append(got, r) }
+			d.decode([]byte(tc.in[:split]), emit)
+			d.decode([]byte(tc.in[split:]), emit)
+			if string(got) != string(tc.want) {
+				t.Errorf("%s split at %d: got %q, want %q", tc.name, split, string(got), string(tc.want))
+			}
+		}
+	}
+}
+
+func TestUTF8DecoderHoldsIncompleteSequence(t *testing.T) {
+	var d utf8Decoder
+	var got []rune
+	d.decode([]byte("a\xf0\x9f"), func(r rune) { got = append(got, r) })
+	if string(got) != "a" {
+		t.Fatalf("got %q before the sequence completed", string(got))
+	}
+	d.decode([]byte("\x98\x80"), func(r rune) { got = append(got, r) })
+	if string(got) != "a😀" {
+		t.Fatalf("got %q, want %q", string(got), "a😀")
+	}
+}
+
+func TestFeedMultibyteByteByByte(t *testing.T) {
+	tb := components.NewTermBuffer(20, 2)
+	s := NewSystem(events.NewBus(), tb)
+
+	in := "héllo 中"
+	for i := 0; i < len(in); i++ {
+		s.feed([]byte{in[i]})
+	}
+
+	want := []rune("héllo 中")
+	for x, r := range want {
+		if g := tb.GetRune(x, 0); g.Rune != r {
+			t.Errorf("cell %d: got %q, want %q", x, g.Rune, r)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"testing"
+
+	"gost/internal/components"
+	"gost/internal/events"
+)
+
+func TestRuneWidth(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want int
+	}{
+		{'a', 1},
+		{'é', 1},
+		{'中', 2},
+		{'ｱ', 1}, // halfwidth katakana
+		{'Ａ', 2}, // fullwidth Latin
+		{'\u0301', 0},
+		{zeroWidthJoiner, 0},
+		{emojiPresentation, 0},
+		{'\U0001F44D', 2},
+		{'☃', 1}, // text-presentation snowman
+	}
+	for _, tt := range tests {
+		if got := runeWidth(tt.r); got != tt.want {
+			t.Errorf("runeWidth(%U) = %d, want %d", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestWideCharAtRightMargin(t *testing.T) {
+	buf := components.NewTermBuffer(5, 3)
+	s := NewSystem(events.NewBus(), buf)
+
+	// One column left: the wide character wraps whole, leaving a blank pad.
+	s.feed([]byte("abcd中"))
+	if g := buf.GetRune(4, 0); g.Rune != ' ' || !buf.IsWrapped(0) {
+		t.Fatalf("pad = %q, wrapped = %v", g.Rune, buf.IsWrapped(0))
+	}
+	if g := buf.GetRune(0, 1); g.Rune != '中' || !g.IsWide() || !buf.GetRune(1, 1).IsWideCont() {
+		t.Fatalf("row 1 starts with %q", g.Rune)
+	}
+	if s.cx != 2 || s.cy != 1 {
+		t.Fatalf("cursor at (%d,%d), want (2,1)", s.cx, s.cy)
+	}
+
+	// Without autowrap it overwrites the last two columns instead.
+	s.feed([]byte("\x1b[?7l\x1b[3;1Habcd文"))
+	if g := buf.GetRune(3, 2); g.Rune != '文' || !g.IsWide() || !buf.GetRune(4, 2).IsWideCont() {
+		t.Fatalf("no-wrap cell 3 = %q", g.Rune)
+	}
+	if buf.IsWrapped(2) {
+		t.Fatal("row 2 wrapped with autowrap off")
+	}
+}
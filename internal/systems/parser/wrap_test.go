@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"testing"
+
+	"gost/internal/components"
+	"gost/internal/events"
+)
+
+func TestPendingWrap(t *testing.T) {
+	tests := []struct {
+		name, between string
+		x, y          int  // where the next 'X' lands
+		last          rune // what is left in the last column of row 0
+	}{
+		{"plain", "", 0, 1, '9'},
+		{"SGR", "\x1b[1;31m", 0, 1, '9'},
+		{"DSR", "\x1b[6n", 0, 1, '9'},
+		{"DA1", "\x1b[c", 0, 1, '9'},
+		{"DECRQM", "\x1b[?7$p", 0, 1, '9'},
+		{"DECSET", "\x1b[?25h", 0, 1, '9'},
+		{"kitty push", "\x1b[>1u", 0, 1, '9'},
+		{"XTWINOPS", "\x1b[22;0t", 0, 1, '9'},
+		{"CR", "\r", 0, 0, '9'},
+		{"CUB", "\x1b[D", 8, 0, '9'},
+		{"CUF", "\x1b[C", 9, 0, 'X'},
+		{"CUP", "\x1b[1;10H", 9, 0, 'X'},
+		{"EL", "\x1b[K", 9, 0, 'X'},
+		{"DCH", "\x1b[P", 9, 0, 'X'},
+		{"DECSC/DECRC", "\x1b7\x1b8", 9, 0, 'X'},
+	}
+	for _, tt := range tests {
+		buf := components.NewTermBuffer(10, 3)
+		s := NewSystem(events.NewBus(), buf)
+		s.feed([]byte("0123456789" + tt.between + "X"))
+
+		if got := buf.GetRune(tt.x, tt.y).Rune; got != 'X' {
+			t.Errorf("%s: cell (%d,%d) = %q, want 'X'", tt.name, tt.x, tt.y, got)
+		}
+		if got := buf.GetRune(9, 0).Rune; got != tt.last {
+			t.Errorf("%s: last column = %q, want %q", tt.name, got, tt.last)
+		}
+		if wrapped := tt.y == 1; buf.IsWrapped(0) != wrapped {
+			t.Errorf("%s: row 0 wrapped = %v, want %v", tt.name, buf.IsWrapped(0), wrapped)
+		}
+	}
+}
+
+func TestPendingWrapREP(t *testing.T) {
+	buf := components.NewTermBuffer(10, 3)
+	s := NewSystem(events.NewBus(), buf)
+	s.feed([]byte("012345678Z\x1b[2b"))
+	if got := rowText(buf, 1, 2); got != "ZZ" {
+		t.Fatalf("REP after the last column printed %q on the next row", got)
+	}
+	if s.cx != 2 || s.cy != 1 {
+		t.Fatalf("cursor at (%d,%d), want (2,1)", s.cx, s.cy)
+	}
+}
+
+func TestExactFitThenNewline(t *testing.T) {
+	buf := components.NewTermBuffer(10, 3)
+	s := NewSystem(events.NewBus(), buf)
+	s.feed([]byte("0123456789\r\nab"))
+	if s.cx != 2 || s.cy != 1 {
+		t.Fatalf("cursor at (%d,%d), want (2,1)", s.cx, s.cy)
+	}
+	if buf.IsWrapped(0) {
+		t.Fatal("a line ended by CRLF was marked wrapped")
+	}
+}
+
+func TestAutoWrapOff(t *testing.T) {
+	buf := components.NewTermBuffer(10, 3)
+	s := NewSystem(events.NewBus(), buf)
+	s.feed([]byte("\x1b[?7lABCDEFGHIJKLM"))
+	if got := rowText(buf, 0, 10); got != "ABCDEFGHIM" {
+		t.Fatalf("row = %q, want the last column overwritten", got)
+	}
+	if s.cy != 0 || buf.IsWrapped(0) {
+		t.Fatalf("DECAWM off moved to row %d, wrapped = %v", s.cy, buf.IsWrapped(0))
+	}
+
+	// Turning it back on does not wrap retroactively.
+	s.feed([]byte("\x1b[?7hN"))
+	if got := buf.GetRune(9, 0).Rune; got != 'N' || s.cy != 0 {
+		t.Fatalf("last column = %q on row %d", got, s.cy)
+	}
+	s.feed([]byte("O"))
+	if got := buf.GetRune(0, 1).Rune; got != 'O' {
+		t.Fatalf("next character landed elsewhere: row 1 col 0 = %q", got)
+	}
+}
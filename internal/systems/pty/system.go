@@ -6,11 +6,10 @@ import (
 	"log"
 	"os"
 	"os/exec"
-	"os/signal"
 	"sync"
-	"syscall"
 
 	"github.com/creack/pty"
+	"gost/internal/components"
 	"gost/internal/events"
 	"gost/internal/systems/input"
 )
@@ -20,8 +19,9 @@ import (
 // -----------------------------------------------------------------------------
 
 var globalPTY struct {
-	mu sync.Mutex
-	f  *os.File
+	mu   sync.Mutex
+	f    *os.File
+	size pty.Winsize // window size of the grid, applied to every new shell
 }
 
 // -----------------------------------------------------------------------------
@@ -44,20 +44,25 @@ func NewSystem(bus *events.Bus) *System {
 	}
 
 	// keyboard → PTY
-	input.WriteToPTY = func(b []byte) {
-		globalPTY.mu.Lock()
-		defer globalPTY.mu.Unlock()
-		if globalPTY.f != nil {
-			if _, err := globalPTY.f.Write(b); err != nil {
-				log.Println("[PTY] write error:", err)
-			}
-		}
-	}
+	input.WriteToPTY = writePTY
 
 	ps.subscribeConfigChanges()
+	ps.subscribeReplies()
+	ps.subscribeResize()
 	return ps
 }
 
+// writePTY sends bytes to the running shell, if any.
+func writePTY(b []byte) {
+	globalPTY.mu.Lock()
+	defer globalPTY.mu.Unlock()
+	if globalPTY.f != nil {
+		if _, err := globalPTY.f.Write(b); err != nil {
+			log.Println("[PTY] write error:", err)
+		}
+	}
+}
+
 func (s *System) UpdateECS() {
 	if s.started {
 		return
@@ -74,7 +79,14 @@ func (s *System) launchShell() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	f, cmd, err := startShell(s.shell)
+	globalPTY.mu.Lock()
+	size := globalPTY.size
+	globalPTY.mu.Unlock()
+	if size.Cols == 0 || size.Rows == 0 {
+		size = pty.Winsize{Cols: 80, Rows: 24} // no layout yet
+	}
+
+	f, cmd, err := startShell(s.shell, &size)
 	if err != nil {
 		log.Println("[PTY] start failed:", err)
 		s.bus.Publish("pty_restart_failed", err.Error())
@@ -93,7 +105,6 @@ func (s *System) launchShell() {
 	log.Println("[PTY] started shell:", s.shell)
 	s.bus.Publish("pty_restarted", s.shell)
 
-	startResizeWatcher(f)
 	go s.readLoop(f, cmd)
 }
 
@@ -101,10 +112,10 @@ func (s *System) launchShell() {
 // Shell + IO
 // -----------------------------------------------------------------------------
 
-func startShell(shell string) (*os.File, *exec.Cmd, error) {
+func startShell(shell string, size *pty.Winsize) (*os.File, *exec.Cmd, error) {
 	cmd := exec.Command(shell, "-i")
 	cmd.Env = append(os.Environ(), "TERM=xterm-256color")
-	f, err := pty.Start(cmd)
+	f, err := pty.StartWithSize(cmd, size)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -136,21 +147,40 @@ func (s *System) readLoop(f *os.File, cmd *exec.Cmd) {
 }
 
 // -----------------------------------------------------------------------------
-// Resize watcher
+// Window size
 // -----------------------------------------------------------------------------
 
-// startResizeWatcher adjusts PTY size on SIGWINCH.
-func startResizeWatcher(f *os.File) {
-	sigs := make(chan os.Signal, 1)
-	signal.Notify(sigs, syscall.SIGWINCH)
+// subscribeResize sets the PTY window size (TIOCSWINSZ) to the grid size
+// published by the renderer; the kernel then signals SIGWINCH to the shell.
+func (s *System) subscribeResize() {
+	if s.bus == nil {
+		return
+	}
+	sub := s.bus.Subscribe("term_resize")
 	go func() {
-		for range sigs {
-			if err := pty.InheritSize(os.Stdin, f); err != nil {
-				log.Println("[PTY] resize error:", err)
+		for evt := range sub {
+			if size, ok := evt.(components.TermSize); ok {
+				setSize(size)
 			}
 		}
 	}()
-	_ = pty.InheritSize(os.Stdin, f)
+}
+
+func setSize(size components.TermSize) {
+	globalPTY.mu.Lock()
+	defer globalPTY.mu.Unlock()
+	globalPTY.size = pty.Winsize{
+		Rows: uint16(size.Rows),
+		Cols: uint16(size.Cols),
+		X:    uint16(size.PixelW),
+		Y:    uint16(size.PixelH),
+	}
+	if globalPTY.f == nil {
+		return
+	}
+	if err := pty.Setsize(globalPTY.f, &globalPTY.size); err != nil {
+		log.Println("[PTY] resize error:", err)
+	}
 }
 
 // -----------------------------------------------------------------------------
@@ -171,6 +201,22 @@ func (s *System) subscribeConfigChanges() {
 	}()
 }
 
+// subscribeReplies forwards terminal responses (device reports, etc.)
+// published by the parser back to the shell.
+func (s *System) subscribeReplies() {
+	if s.bus == nil {
+		return
+	}
+	sub := s.bus.Subscribe("pty_write")
+	go func() {
+		for evt := range sub {
+			if b, ok := evt.([]byte); ok {
+				writePTY(b)
+			}
+		}
+	}()
+}
+
 func (s *System) restartShell(newShell string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
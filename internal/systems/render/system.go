@@ -3,8 +3,10 @@ package render
 import (
 	"image/color"
 	"sync"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"golang.org/x/image/font"
@@ -27,9 +29,10 @@ type System struct {
 	bus        *events.Bus
 	term       *components.TermBuffer
 	scrollback *components.Scrollback
+	modes      *components.ModeTable // terminal modes set by the parser
 	viewport   *Viewport
 
-	fontFace font.Face
+	fontFace     font.Face
 	cellW, cellH int
 
 	scrollOffset int
@@ -39,8 +42,25 @@ type System struct {
 	bgColor      color.Color
 
 	offsetSub <-chan events.Event // scroll offset listener
+
+	frame      *ebiten.Image // last composed frame, held during synchronized output
+	syncOutput bool          // mode 2026 active
+	syncSince  time.Time
+
+	hoverLink uint32 // hyperlink under the mouse pointer, 0 if none
+
+	gridSize    components.TermSize // last size published on "term_resize"
+	pendingSize components.TermSize // size the window settles on
+	pendingAt   time.Time
 }
 
+// syncTimeout bounds how long a synchronized update may freeze the screen.
+const syncTimeout = 150 * time.Millisecond
+
+// resizeDebounce is how long the window size must hold still before the
+// grid and the PTY follow it, so a drag does not reflow on every frame.
+const resizeDebounce = 100 * time.Millisecond
+
 // NewSystem initializes the renderer and prepares background tiles.
 func NewSystem(bus *events.Bus) *System {
 	r := &System{
@@ -74,6 +94,13 @@ func (r *System) AttachScrollback(sb *components.Scrollback) {
 	r.tryInitViewport()
 }
 
+// AttachModes gives the renderer the parser's mode table, read every frame.
+func (r *System) AttachModes(modes *components.ModeTable) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.modes = modes
+}
+
 func (r *System) tryInitViewport() {
 	if r.viewport == nil && r.term != nil && r.scrollback != nil && r.bus != nil {
 		r.viewport = NewViewport(r.scrollback, r.term, r.bus)
@@ -104,6 +131,17 @@ func (r *System) subscribeScrollOffset() {
 	}()
 }
 
+// pollModes tracks synchronized output (mode 2026).
+func (r *System) pollModes() {
+	if r.modes == nil {
+		return
+	}
+	if on := r.modes.Enabled(components.ModeSyncOutput); on != r.syncOutput {
+		r.syncOutput = on
+		r.syncSince = time.Now()
+	}
+}
+
 // -----------------------------------------------------------------------------
 // ECS integration
 // -----------------------------------------------------------------------------
@@ -114,13 +152,26 @@ func (r *System) UpdateECS() {
 	}
 }
 
+// Layout maps the window 1:1 onto the screen and derives the grid size from
+// the cell size. A changed size is published on "term_resize" once it has
+// been stable for resizeDebounce; the first one goes out immediately.
 func (r *System) Layout(outW, outH int) (int, int) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	if r.term != nil {
-		return r.term.Width * r.cellW, r.term.Height * r.cellH
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	size := components.TermSize{Cols: max(outW/r.cellW, 1), Rows: max(outH/r.cellH, 1)}
+	size.PixelW, size.PixelH = size.Cols*r.cellW, size.Rows*r.cellH
+	if size != r.pendingSize {
+		r.pendingSize, r.pendingAt = size, time.Now()
+	}
+	first := r.gridSize == components.TermSize{}
+	if r.pendingSize != r.gridSize && (first || time.Since(r.pendingAt) >= resizeDebounce) {
+		r.gridSize = r.pendingSize
+		if r.bus != nil {
+			r.bus.Publish("term_resize", r.gridSize)
+		}
 	}
-	return 640, 384
+	return outW, outH
 }
 
 func (r *System) Buffer() *components.TermBuffer {
@@ -134,39 +185,118 @@ func (r *System) Buffer() *components.TermBuffer {
 // -----------------------------------------------------------------------------
 
 func (r *System) Draw(screen *ebiten.Image) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
+	// While the application batches an update, keep showing the last frame.
+	r.pollModes()
+	held := r.syncOutput && time.Since(r.syncSince) < syncTimeout
+	if held && r.frame != nil && r.frame.Bounds() == screen.Bounds() {
+		screen.DrawImage(r.frame, nil)
+		return
+	}
+	if r.frame == nil || r.frame.Bounds() != screen.Bounds() {
+		r.frame = ebiten.NewImage(screen.Bounds().Dx(), screen.Bounds().Dy())
+	}
+	r.compose(r.frame)
+	screen.DrawImage(r.frame, nil)
+}
+
+// compose renders the terminal contents onto dst.
+func (r *System) compose(screen *ebiten.Image) {
 	screen.Fill(r.bgColor)
 	if r.term == nil {
 		return
 	}
 
 	lines := r.composeVisibleLines()
+	r.updateHover(lines)
 	for y := 0; y < len(lines); y++ {
 		row := lines[y]
 		for x := 0; x < r.term.Width && x < len(row); x++ {
-			g := row[x]
+			// The leading cell of a wide character paints both halves.
+			if row[x].IsWideCont() && x > 0 && row[x-1].IsWide() {
+				continue
+			}
+			r.drawGlyph(screen, x, y, row[x])
+		}
+	}
+}
 
-			bgColor := r.resolveColor(g.Bg, false)
+// drawGlyph paints one cell (two for a wide character): background, text,
+// then attribute decorations.
+func (r *System) drawGlyph(screen *ebiten.Image, x, y int, g components.Glyph) {
+	px, py := x*r.cellW, y*r.cellH
+	w := r.cellW
+	if g.IsWide() {
+		w *= 2
+	}
+	fgColor := r.resolveColor(g.Fg, true)
+
+	if g.Attr.Has(components.AttrReverse) {
+		// Reverse video: the foreground fills the cell, text uses the background.
+		ebitenutil.DrawRect(screen, float64(px), float64(py),
+			float64(w), float64(r.cellH), fgColor)
+		fgColor = r.resolveColor(g.Bg, false)
+	} else {
+		if tile := r.bgTile(g.Bg); tile != nil && !g.IsWide() {
 			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Translate(float64(x*r.cellW), float64(y*r.cellH))
-
-			if g.Bg < len(r.bgTiles) && r.bgTiles[g.Bg] != nil {
-				screen.DrawImage(r.bgTiles[g.Bg], op)
-			} else {
-				tile := ebiten.NewImage(r.cellW, r.cellH)
-				tile.Fill(bgColor)
-				screen.DrawImage(tile, op)
-			}
+			op.GeoM.Translate(float64(px), float64(py))
+			screen.DrawImage(tile, op)
+		} else {
+			ebitenutil.DrawRect(screen, float64(px), float64(py),
+				float64(w), float64(r.cellH), r.resolveColor(g.Bg, false))
+		}
+	}
 
-			if g.Rune == 0 || g.Rune == ' ' {
-				continue
-			}
-			fgColor := r.resolveColor(g.Fg, true)
-			text.Draw(screen, string(g.Rune), r.fontFace,
-				x*r.cellW, y*r.cellH+r.cellH-2, fgColor)
+	if g.Attr.Has(components.AttrHidden) {
+		return
+	}
+	if g.Attr.Has(components.AttrDim) {
+		fgColor = blend(fgColor, r.resolveColor(g.Bg, false), 0.5)
+	}
+
+	if g.Rune != 0 && g.Rune != ' ' {
+		op := &ebiten.DrawImageOptions{}
+		if g.Attr.Has(components.AttrItalic) {
+			op.GeoM.Skew(-0.2, 0)
 		}
+		op.GeoM.Translate(float64(px), float64(py+r.cellH-2))
+		op.ColorScale.ScaleWithColor(fgColor)
+		text.DrawWithOptions(screen, g.Text(), r.fontFace, op)
+		if g.Attr.Has(components.AttrBold) {
+			// basicfont has no bold face; overstrike one pixel to the right.
+			op.GeoM.Translate(1, 0)
+			text.DrawWithOptions(screen, g.Text(), r.fontFace, op)
+		}
+	}
+
+	hovered := g.Link != 0 && g.Link == r.hoverLink
+	if g.Attr.Has(components.AttrUnderline) || hovered {
+		ebitenutil.DrawRect(screen, float64(px), float64(py+r.cellH-1),
+			float64(w), 1, fgColor)
+	}
+	if g.Attr.Has(components.AttrStrike) {
+		ebitenutil.DrawRect(screen, float64(px), float64(py+r.cellH/2),
+			float64(w), 1, fgColor)
+	}
+}
+
+// updateHover finds the hyperlink under the mouse pointer and announces a
+// change on "link_hover" with its URI ("" when leaving a link).
+func (r *System) updateHover(lines [][]components.Glyph) {
+	var link uint32
+	px, py := ebiten.CursorPosition()
+	x, y := px/r.cellW, py/r.cellH
+	if px >= 0 && py >= 0 && y < len(lines) && x < len(lines[y]) {
+		link = lines[y][x].Link
+	}
+	if link == r.hoverLink {
+		return
+	}
+	r.hoverLink = link
+	if r.bus != nil {
+		r.bus.Publish("link_hover", components.LinkURI(link))
 	}
 }
 
@@ -214,12 +344,6 @@ func (v *Viewport) HandleScrollInput() {
 		}
 	}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyPageUp) {
-		v.bus.Publish("scroll_up", nil)
-	} else if inpututil.IsKeyJustPressed(ebiten.KeyPageDown) {
-		v.bus.Publish("scroll_down", nil)
-	}
-
 	if ebiten.IsKeyPressed(ebiten.KeyControl) && inpututil.IsKeyJustPressed(ebiten.KeyEnd) {
 		v.bus.Publish("scroll_reset_request", nil)
 	}
@@ -268,6 +392,18 @@ func (r *System) precacheTiles() {
 	}
 }
 
+// bgTile returns the cached background tile for default and base palette
+// colors, or nil when the cell needs a one-off fill.
+func (r *System) bgTile(c components.Color) *ebiten.Image {
+	if c.IsDefault() {
+		return r.bgTiles[0]
+	}
+	if idx, ok := c.Index(); ok && idx < len(r.bgTiles) {
+		return r.bgTiles[idx]
+	}
+	return nil
+}
+
 func make256Color(index int) colorRGBA {
 	switch {
 	case index < 16:
@@ -284,7 +420,29 @@ func make256Color(index int) colorRGBA {
 	}
 }
 
-func (r *System) resolveColor(idx int, isForeground bool) color.Color {
+// blend mixes a toward b by t (0 = a, 1 = b); used for dim text.
+func blend(a, b color.Color, t float64) color.Color {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, _ := b.RGBA()
+	mix := func(x, y uint32) uint8 {
+		return uint8((float64(x)*(1-t) + float64(y)*t) / 257)
+	}
+	return color.RGBA{mix(ar, br), mix(ag, bg), mix(ab, bb), uint8(aa / 257)}
+}
+
+// resolveColor maps a cell color to RGBA. Default colors use palette entry 7
+// (foreground) or 0 (background); truecolor values are drawn exactly.
+func (r *System) resolveColor(c components.Color, isForeground bool) color.Color {
+	if red, green, blue, ok := c.RGB(); ok {
+		return color.RGBA{red, green, blue, 255}
+	}
+	idx, ok := c.Index()
+	if !ok {
+		if isForeground {
+			return r.fgPalette[7].toColor()
+		}
+		return r.bgPalette[0].toColor()
+	}
 	if idx >= 0 && idx < 8 {
 		if isForeground {
 			return r.fgPalette[idx].toColor()
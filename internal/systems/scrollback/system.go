@@ -71,6 +71,7 @@ func (s *System) subscribeScrollEvents() {
     subScrollReset := s.bus.Subscribe("scroll_reset_request")
     subScrollPageUp := s.bus.Subscribe("scroll_page_up")
     subScrollPageDown := s.bus.Subscribe("scroll_page_down")
+    subResize := s.bus.Subscribe("term_resize")
 
     go func() {
         for range subScrollUp {
@@ -97,5 +98,13 @@ func (s *System) subscribeScrollEvents() {
             s.Reset()
         }
     }()
+    go func() {
+        for evt := range subResize {
+            // The terminal re-flows the history along with the screen.
+            if _, ok := evt.(components.TermSize); ok && s.scrollback != nil {
+                s.Reset() // the old offset no longer points at the same text
+            }
+        }
+    }()
 }
 
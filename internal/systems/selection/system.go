@@ -7,6 +7,7 @@ import (
 
 	"gost/internal/components"
 	"gost/internal/events"
+	"gost/internal/systems/config"
 	"gost/internal/util"
 )
 
@@ -19,10 +20,12 @@ type System struct {
 	bus    *events.Bus
 	mu     sync.RWMutex
 
-	selecting bool
+	selecting      bool
 	startX, startY int
 	endX, endY     int
 	cellW, cellH   int
+
+	copyURL bool // append hyperlink targets to copied text
 }
 
 // NewSystem initializes a new selection handler with ECS bus linkage.
@@ -51,6 +54,8 @@ func (s *System) subscribeEvents() {
 	endSub := s.bus.Subscribe("selection_end")
 	clearSub := s.bus.Subscribe("selection_clear")
 	copySub := s.bus.Subscribe("selection_copy")
+	loadedSub := s.bus.Subscribe("config_loaded")
+	changedSub := s.bus.Subscribe("config_changed")
 
 	go func() {
 		for evt := range startSub {
@@ -83,6 +88,23 @@ func (s *System) subscribeEvents() {
 			s.CopyToClipboard()
 		}
 	}()
+	applyConfig := func(evt events.Event) {
+		if cfg, ok := evt.(*config.RootConfig); ok {
+			s.mu.Lock()
+			s.copyURL = cfg.Links.CopyURL
+			s.mu.Unlock()
+		}
+	}
+	go func() {
+		for evt := range loadedSub {
+			applyConfig(evt)
+		}
+	}()
+	go func() {
+		for evt := range changedSub {
+			applyConfig(evt)
+		}
+	}()
 }
 
 // -----------------------------------------------------------------------------
@@ -114,6 +136,14 @@ func (s *System) EndSelection(px, py int) {
 	s.selecting = true // keep visible until cleared manually
 	s.endX, s.endY = s.pixelToCell(px, py)
 	s.bus.Publish("selection_finished", s.Bounds())
+
+	// As in other X11 terminals, selecting text sets PRIMARY; a plain
+	// click (no drag) leaves it alone.
+	if s.startX != s.endX || s.startY != s.endY {
+		if text := s.selectedText(); text != "" {
+			util.SetSelectionString(util.Primary, text)
+		}
+	}
 }
 
 func (s *System) Clear() {
@@ -136,25 +166,48 @@ func (s *System) CopyToClipboard() {
 		return
 	}
 
+	text := s.selectedText()
+	if text == "" {
+		return
+	}
+
+	util.SetClipboardString(text)
+	log.Printf("[Selection] Copied %d characters", len(text))
+	s.bus.Publish("selection_copied", text)
+}
+
+// selectedText returns the text under the selection; s.mu must be held.
+func (s *System) selectedText() string {
+	if s.buffer == nil {
+		return ""
+	}
 	b := s.Bounds()
 	var sb strings.Builder
+	var link uint32 // hyperlink of the run being copied
+	endLink := func(next uint32) {
+		if s.copyURL && link != 0 && next != link {
+			sb.WriteString(" <" + components.LinkURI(link) + ">")
+		}
+		link = next
+	}
 	for y := b["y1"]; y <= b["y2"] && y < s.buffer.Height; y++ {
 		for x := b["x1"]; x <= b["x2"] && x < s.buffer.Width; x++ {
 			g := s.buffer.GetRune(x, y)
-			sb.WriteRune(g.Rune)
+			if g.IsWideCont() {
+				continue // copied with its leading cell
+			}
+			endLink(g.Link)
+			sb.WriteString(g.Text())
 		}
-		if y < b["y2"] {
+		// A soft-wrapped row continues on the next one: no newline.
+		wrapped := b["x2"] >= s.buffer.Width-1 && s.buffer.IsWrapped(y)
+		if y < b["y2"] && !wrapped {
+			endLink(0)
 			sb.WriteByte('\n')
 		}
 	}
-	text := sb.String()
-	if text == "" {
-		return
-	}
-
-	util.SetClipboardString(text)
-	log.Printf("[Selection] Copied %d characters", len(text))
-	s.bus.Publish("selection_copied", text)
+	endLink(0)
+	return sb.String()
 }
 
 // -----------------------------------------------------------------------------
@@ -170,6 +223,15 @@ func (s *System) Bounds() map[string]int {
 	if sy > ey {
 		sy, ey = ey, sy
 	}
+	// Never split a wide character: widen the edges to cover both halves.
+	if s.buffer != nil {
+		if sx > 0 && s.buffer.GetRune(sx, sy).IsWideCont() {
+			sx--
+		}
+		if s.buffer.GetRune(ex, ey).IsWide() {
+			ex++
+		}
+	}
 	return map[string]int{"x1": sx, "y1": sy, "x2": ex, "y2": ey}
 }
 
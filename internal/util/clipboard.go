@@ -1,18 +1,87 @@
 package util
 
-import "log"
+import (
+	"log"
+	"sync"
+)
 
-// clipboardBuffer acts as an in-memory clipboard fallback.
-var clipboardBuffer string
+// -----------------------------------------------------------------------------
+// Clipboard
+// -----------------------------------------------------------------------------
 
-// SetClipboardString saves text to clipboard (or buffer if unsupported).
+// Selection names one of the system clipboards.
+type Selection int
+
+const (
+	Clipboard Selection = iota // explicit copy/paste (X11 CLIPBOARD)
+	Primary                    // last selected text, pasted by middle-click (X11 PRIMARY)
+)
+
+// clipboardBackend reaches the system clipboard.
+type clipboardBackend interface {
+	Set(sel Selection, text string) error
+	Get(sel Selection) (string, error)
+}
+
+var clipboard struct {
+	once    sync.Once
+	backend clipboardBackend // nil when only the in-memory buffers are available
+
+	mu  sync.Mutex
+	buf [2]string // in-memory copy of each selection, the fallback for headless runs
+}
+
+// initClipboard connects to the system clipboard on first use.
+func initClipboard() {
+	clipboard.once.Do(func() {
+		b, err := newSystemClipboard()
+		if err != nil {
+			log.Println("[Clipboard] no system clipboard, using in-memory buffer:", err)
+			return
+		}
+		clipboard.backend = b
+	})
+}
+
+// SetSelectionString stores text in the given selection and mirrors it in
+// the in-memory buffer.
+func SetSelectionString(sel Selection, s string) {
+	initClipboard()
+	clipboard.mu.Lock()
+	clipboard.buf[sel] = s
+	clipboard.mu.Unlock()
+
+	if clipboard.backend == nil {
+		log.Printf("[Clipboard] Copied %d bytes (fallback buffer)", len(s))
+		return
+	}
+	if err := clipboard.backend.Set(sel, s); err != nil {
+		log.Println("[Clipboard] set failed, kept in buffer:", err)
+	}
+}
+
+// SelectionString returns the contents of the given selection, falling back
+// to the last value stored by this process.
+func SelectionString(sel Selection) string {
+	initClipboard()
+	if clipboard.backend != nil {
+		s, err := clipboard.backend.Get(sel)
+		if err == nil {
+			return s
+		}
+		log.Println("[Clipboard] get failed, using buffer:", err)
+	}
+	clipboard.mu.Lock()
+	defer clipboard.mu.Unlock()
+	return clipboard.buf[sel]
+}
+
+// SetClipboardString saves text to the CLIPBOARD selection.
 func SetClipboardString(s string) {
-	clipboardBuffer = s
-	log.Printf("[Clipboard] Copied %d bytes (fallback buffer)", len(s))
+	SetSelectionString(Clipboard, s)
 }
 
-// ClipboardString returns the last copied value.
+// ClipboardString returns the CLIPBOARD selection.
 func ClipboardString() string {
-	return clipboardBuffer
+	return SelectionString(Clipboard)
 }
-
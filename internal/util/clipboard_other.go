@@ -0,0 +1,11 @@
+//go:build !(linux || freebsd || openbsd || netbsd)
+
+package util
+
+import "errors"
+
+// newSystemClipboard reports that no system clipboard backend exists for
+// this platform; the in-memory buffer is used instead.
+func newSystemClipboard() (clipboardBackend, error) {
+	return nil, errors.New("no clipboard backend for this platform")
+}
@@ -0,0 +1,20 @@
+package util
+
+import (
+	"os"
+	"testing"
+)
+
+func TestClipboardFallbackBuffer(t *testing.T) {
+	if os.Getenv("DISPLAY") != "" {
+		t.Skip("DISPLAY is set; the system clipboard is in use")
+	}
+	SetClipboardString("copied")
+	SetSelectionString(Primary, "selected")
+	if got := ClipboardString(); got != "copied" {
+		t.Fatalf("clipboard = %q", got)
+	}
+	if got := SelectionString(Primary); got != "selected" {
+		t.Fatalf("primary = %q", got)
+	}
+}
@@ -0,0 +1,252 @@
+//go:build linux || freebsd || openbsd || netbsd
+
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// -----------------------------------------------------------------------------
+// X11 Clipboard
+// -----------------------------------------------------------------------------
+
+// x11ReadTimeout bounds how long a paste waits for the selection owner.
+const x11ReadTimeout = time.Second
+
+// x11Clipboard owns CLIPBOARD and PRIMARY through a hidden InputOnly window,
+// serves other clients' SelectionRequest events, and converts selections
+// owned by other clients for reading. Transfers are single-shot: INCR is
+// not implemented, so text is limited by the server's request size.
+type x11Clipboard struct {
+	conn *xgb.Conn
+	win  xproto.Window
+
+	atoms struct {
+		clipboard, targets, utf8, text, incr, property xproto.Atom
+	}
+	maxData int // largest property the server accepts in one request
+
+	readMu sync.Mutex // one conversion at a time: they share the property and reads entry
+
+	mu    sync.Mutex
+	owned map[xproto.Atom]string           // text served while we own a selection
+	reads map[xproto.Atom]chan xproto.Atom // pending conversions, answered with the property
+}
+
+// newSystemClipboard connects to the X server named by $DISPLAY.
+func newSystemClipboard() (clipboardBackend, error) {
+	if os.Getenv("DISPLAY") == "" {
+		return nil, errors.New("DISPLAY not set")
+	}
+	return newX11Clipboard("")
+}
+
+func newX11Clipboard(display string) (*x11Clipboard, error) {
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		return nil, err
+	}
+	c := &x11Clipboard{
+		conn:  conn,
+		owned: make(map[xproto.Atom]string),
+		reads: make(map[xproto.Atom]chan xproto.Atom),
+	}
+	if err := c.init(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	go c.eventLoop()
+	return c, nil
+}
+
+func (c *x11Clipboard) init() error {
+	setup := xproto.Setup(c.conn)
+	screen := setup.DefaultScreen(c.conn)
+	c.maxData = int(setup.MaximumRequestLength)*4 - 64
+
+	win, err := xproto.NewWindowId(c.conn)
+	if err != nil {
+		return err
+	}
+	err = xproto.CreateWindowChecked(c.conn, 0, win, screen.Root, 0, 0, 1, 1, 0,
+		xproto.WindowClassInputOnly, screen.RootVisual, 0, nil).Check()
+	if err != nil {
+		return fmt.Errorf("create window: %w", err)
+	}
+	c.win = win
+
+	for name, dst := range map[string]*xproto.Atom{
+		"CLIPBOARD":      &c.atoms.clipboard,
+		"TARGETS":        &c.atoms.targets,
+		"UTF8_STRING":    &c.atoms.utf8,
+		"TEXT":           &c.atoms.text,
+		"INCR":           &c.atoms.incr,
+		"GOST_SELECTION": &c.atoms.property,
+	} {
+		reply, err := xproto.InternAtom(c.conn, false, uint16(len(name)), name).Reply()
+		if err != nil {
+			return fmt.Errorf("intern %s: %w", name, err)
+		}
+		*dst = reply.Atom
+	}
+	return nil
+}
+
+func (c *x11Clipboard) selectionAtom(sel Selection) xproto.Atom {
+	if sel == Primary {
+		return xproto.AtomPrimary
+	}
+	return c.atoms.clipboard
+}
+
+// Set takes ownership of the selection and serves text from now on.
+func (c *x11Clipboard) Set(sel Selection, text string) error {
+	atom := c.selectionAtom(sel)
+	c.mu.Lock()
+	c.owned[atom] = text
+	c.mu.Unlock()
+
+	xproto.SetSelectionOwner(c.conn, c.win, atom, xproto.TimeCurrentTime)
+	reply, err := xproto.GetSelectionOwner(c.conn, atom).Reply()
+	if err != nil {
+		return err
+	}
+	if reply.Owner != c.win {
+		return errors.New("selection ownership refused")
+	}
+	return nil
+}
+
+// Get returns the selection text, asking its owner to convert it to
+// UTF8_STRING when another client holds it.
+func (c *x11Clipboard) Get(sel Selection) (string, error) {
+	atom := c.selectionAtom(sel)
+	owner, err := xproto.GetSelectionOwner(c.conn, atom).Reply()
+	if err != nil {
+		return "", err
+	}
+	switch owner.Owner {
+	case xproto.WindowNone:
+		return "", nil
+	case c.win:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.owned[atom], nil
+	}
+
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+
+	c.mu.Lock()
+	ch := make(chan xproto.Atom, 1)
+	c.reads[atom] = ch
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.reads, atom)
+		c.mu.Unlock()
+	}()
+
+	xproto.ConvertSelection(c.conn, c.win, atom, c.atoms.utf8, c.atoms.property, xproto.TimeCurrentTime)
+	select {
+	case prop := <-ch:
+		if prop == xproto.AtomNone {
+			return "", nil // no owner, or it cannot provide text
+		}
+		reply, err := xproto.GetProperty(c.conn, true, c.win, prop,
+			xproto.GetPropertyTypeAny, 0, uint32(c.maxData/4)).Reply()
+		if err != nil {
+			return "", err
+		}
+		if reply.Type == c.atoms.incr {
+			return "", errors.New("selection too large (INCR transfers are not supported)")
+		}
+		return string(reply.Value), nil
+	case <-time.After(x11ReadTimeout):
+		return "", errors.New("selection owner did not answer")
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Event Loop
+// -----------------------------------------------------------------------------
+
+func (c *x11Clipboard) eventLoop() {
+	for {
+		ev, err := c.conn.WaitForEvent()
+		if ev == nil && err == nil {
+			return // connection closed
+		}
+		switch e := ev.(type) {
+		case xproto.SelectionRequestEvent:
+			c.serve(e)
+		case xproto.SelectionClearEvent:
+			c.mu.Lock()
+			delete(c.owned, e.Selection)
+			c.mu.Unlock()
+		case xproto.SelectionNotifyEvent:
+			c.mu.Lock()
+			if ch, ok := c.reads[e.Selection]; ok {
+				ch <- e.Property
+				delete(c.reads, e.Selection)
+			}
+			c.mu.Unlock()
+		}
+	}
+}
+
+// serve answers another client's request for a selection we own: TARGETS
+// lists the supported formats, and the text targets receive the text.
+func (c *x11Clipboard) serve(e xproto.SelectionRequestEvent) {
+	prop := e.Property
+	if prop == xproto.AtomNone {
+		prop = e.Target // obsolete clients
+	}
+
+	c.mu.Lock()
+	text, owned := c.owned[e.Selection]
+	c.mu.Unlock()
+
+	switch {
+	case !owned:
+		prop = xproto.AtomNone
+	case e.Target == c.atoms.targets:
+		targets := []xproto.Atom{c.atoms.targets, c.atoms.utf8, c.atoms.text, xproto.AtomString}
+		data := make([]byte, 4*len(targets))
+		for i, a := range targets {
+			xgb.Put32(data[4*i:], uint32(a))
+		}
+		xproto.ChangeProperty(c.conn, xproto.PropModeReplace, e.Requestor, prop,
+			xproto.AtomAtom, 32, uint32(len(targets)), data)
+	case e.Target == c.atoms.utf8 || e.Target == c.atoms.text || e.Target == xproto.AtomString:
+		if len(text) > c.maxData {
+			prop = xproto.AtomNone // too large without INCR
+			break
+		}
+		typ := c.atoms.utf8
+		if e.Target == xproto.AtomString {
+			typ = xproto.AtomString
+		}
+		xproto.ChangeProperty(c.conn, xproto.PropModeReplace, e.Requestor, prop,
+			typ, 8, uint32(len(text)), []byte(text))
+	default:
+		prop = xproto.AtomNone
+	}
+
+	notify := xproto.SelectionNotifyEvent{
+		Time:      e.Time,
+		Requestor: e.Requestor,
+		Selection: e.Selection,
+		Target:    e.Target,
+		Property:  prop,
+	}
+	xproto.SendEvent(c.conn, false, e.Requestor, xproto.EventMaskNoEvent, string(notify.Bytes()))
+}
@@ -0,0 +1,56 @@
+//go:build linux || freebsd || openbsd || netbsd
+
+package util
+
+import (
+	"os"
+	"testing"
+)
+
+// The X11 tests need a server, e.g. xvfb-run go test ./internal/util/.
+
+func newTestX11(t *testing.T) *x11Clipboard {
+	t.Helper()
+	if os.Getenv("DISPLAY") == "" {
+		t.Skip("DISPLAY not set; run under Xvfb")
+	}
+	c, err := newX11Clipboard("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.conn.Close)
+	return c
+}
+
+func TestX11ServesOtherClients(t *testing.T) {
+	owner, reader := newTestX11(t), newTestX11(t)
+	for _, sel := range []Selection{Clipboard, Primary} {
+		if err := owner.Set(sel, "héllo wörld"); err != nil {
+			t.Fatal(err)
+		}
+		got, err := reader.Get(sel)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "héllo wörld" {
+			t.Fatalf("selection %d: got %q", sel, got)
+		}
+	}
+}
+
+func TestX11LosesOwnership(t *testing.T) {
+	a, b := newTestX11(t), newTestX11(t)
+	if err := a.Set(Clipboard, "first"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Set(Clipboard, "second"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := a.Get(Clipboard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "second" {
+		t.Fatalf("got %q after another client took the selection", got)
+	}
+}
@@ -0,0 +1,79 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultOpener is used when no link opener is configured.
+const DefaultOpener = "xdg-open"
+
+// DefaultSchemes are the URI schemes opened when none are configured. file
+// is left out: a link to a local .desktop file or executable would let any
+// program writing to the terminal run it on a Ctrl+click.
+var DefaultSchemes = []string{"http", "https", "mailto"}
+
+// endOfOptions lists openers that accept "--" before the URI. xdg-open and
+// open reject it; for them the scheme check alone keeps a URI from being
+// read as an option, since a scheme cannot start with "-".
+var endOfOptions = map[string]bool{
+	"gio":       true,
+	"kde-open":  true,
+	"kde-open5": true,
+}
+
+// OpenURI starts opener with uri as its last argument and does not wait
+// for it. opener is split on spaces, so it may carry its own arguments
+// ("firefox --new-tab"); no shell is involved. Only URIs with one of the
+// given schemes, or DefaultSchemes when none are given, are opened.
+func OpenURI(opener, uri string, schemes []string) error {
+	if err := checkURI(uri, schemes); err != nil {
+		return err
+	}
+	args := openerArgs(opener, uri)
+	cmd := exec.Command(args[0], args[1:]...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait() // reap the child
+	return nil
+}
+
+// checkURI rejects URIs that do not parse or whose scheme is not allowed.
+func checkURI(uri string, schemes []string) error {
+	if uri == "" {
+		return errors.New("empty URI")
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" {
+		return fmt.Errorf("URI %q has no scheme", uri)
+	}
+	if len(schemes) == 0 {
+		schemes = DefaultSchemes
+	}
+	for _, s := range schemes {
+		if strings.EqualFold(u.Scheme, s) {
+			return nil
+		}
+	}
+	return fmt.Errorf("URI scheme %q is not allowed", u.Scheme)
+}
+
+// openerArgs returns the command line that opens uri.
+func openerArgs(opener, uri string) []string {
+	args := strings.Fields(opener)
+	if len(args) == 0 {
+		args = []string{DefaultOpener}
+	}
+	if endOfOptions[filepath.Base(args[0])] {
+		args = append(args, "--")
+	}
+	return append(args, uri)
+}
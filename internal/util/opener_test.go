@@ -0,0 +1,51 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckURI(t *testing.T) {
+	tests := []struct {
+		uri     string
+		schemes []string
+		ok      bool
+	}{
+		{"https://example.com/", nil, true},
+		{"HTTP://example.com/", nil, true},
+		{"mailto:someone@example.com", nil, true},
+		{"file:///tmp/x", nil, false},
+		{"file:///tmp/x", []string{"file"}, true},
+		{"javascript:alert(1)", nil, false},
+		{"ssh://host", nil, false},
+		{"ssh://host", []string{"ssh"}, true},
+		{"https://example.com/", []string{"ssh"}, false},
+		{"--help", nil, false},
+		{"-x:y", nil, false},
+		{"example.com", nil, false},
+		{"", nil, false},
+	}
+	for _, tt := range tests {
+		if err := checkURI(tt.uri, tt.schemes); (err == nil) != tt.ok {
+			t.Errorf("checkURI(%q, %q) = %v, want ok=%v", tt.uri, tt.schemes, err, tt.ok)
+		}
+	}
+}
+
+func TestOpenerArgs(t *testing.T) {
+	tests := []struct {
+		opener string
+		want   []string
+	}{
+		{"", []string{"xdg-open", "https://a"}},
+		{"xdg-open", []string{"xdg-open", "https://a"}},
+		{"firefox --new-tab", []string{"firefox", "--new-tab", "https://a"}},
+		{"gio open", []string{"gio", "open", "--", "https://a"}},
+		{"/usr/bin/kde-open5", []string{"/usr/bin/kde-open5", "--", "https://a"}},
+	}
+	for _, tt := range tests {
+		if got := openerArgs(tt.opener, "https://a"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("openerArgs(%q) = %q, want %q", tt.opener, got, tt.want)
+		}
+	}
+}